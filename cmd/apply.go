@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/midnattsol/docker-sweep/internal/docker"
+	"github.com/midnattsol/docker-sweep/internal/sweep"
+	"github.com/midnattsol/docker-sweep/internal/ui"
+)
+
+func NewApplyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "apply <manifest.json>",
+		Short: "Delete the resources listed in a dry-run manifest",
+		Long: `Delete exactly the resources listed in a manifest produced by
+--dry-run --output json, without analyzing again. Resources that no longer
+exist are counted as removed. Use - to read the manifest from stdin.
+
+Examples:
+  docker sweep --dry-run -o json > plan.json  # Review plan.json, then:
+  docker sweep apply plan.json
+  docker sweep apply plan.json --dry-run      # Show what would be removed`,
+		Args: cobra.ExactArgs(1),
+		RunE: runApply,
+	}
+
+	return cmd
+}
+
+func runApply(cmd *cobra.Command, args []string) error {
+	toDelete, err := readManifest(args[0])
+	if err != nil {
+		printError(err)
+		return err
+	}
+
+	if err := docker.CheckAvailable(); err != nil {
+		printError(err)
+		return err
+	}
+
+	if jsonOutput() {
+		if flagDryRun {
+			return writeJSON(sweep.NewDryRunReport(toDelete))
+		}
+		ctx, stop := deletionContext()
+		defer stop()
+		_, errs := deleteResources(ctx, toDelete, nil)
+		tallyDeleteErrors(errs)
+		return writeJSON(sweep.NewReport(toDelete, errs))
+	}
+
+	printHeader()
+
+	if len(toDelete) == 0 {
+		fmt.Print(ui.RenderNoResources())
+		return nil
+	}
+
+	if flagDryRun {
+		fmt.Print(ui.RenderDryRun(toDelete, nil))
+		return nil
+	}
+
+	if err := deleteWithProgress("Deleting resources...", toDelete); err != nil {
+		printError(err)
+		return err
+	}
+	return nil
+}
+
+// readManifest reads a manifest from path, or from stdin when path is "-"
+func readManifest(path string) ([]sweep.Resource, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read manifest: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+	return sweep.ReadManifest(r)
+}
@@ -1,7 +1,9 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -19,44 +21,76 @@ func NewContainersCmd() *cobra.Command {
 	}
 
 	cmd.Flags().BoolVar(&flagExited, "exited", false, "Only exited containers")
+	cmd.Flags().BoolVar(&flagRespectRestart, "respect-restart-policy", true, "Protect stopped containers whose restart policy is always or unless-stopped")
+	cmd.Flags().BoolVar(&flagCreated, "created", false, "Only containers that were created but never started")
+	cmd.Flags().BoolVar(&flagStop, "stop", false, "Stop running containers, then remove them (Compose and sweep.protect still win)")
+	cmd.Flags().DurationVar(&flagStopTimeout, "stop-timeout", 10*time.Second, "Time to wait for --stop before killing the container")
 
 	return cmd
 }
 
 func runContainers(cmd *cobra.Command, args []string) error {
-	cfg, err := buildConfig()
+	if err := validateTypeSpecificFlags(true, false, false, false); err != nil {
+		printError(err)
+		return err
+	}
+
+	cfg, err := buildConfig(cmd)
 	if err != nil {
-		fmt.Print(ui.RenderError(err.Error()))
+		printError(err)
+		return err
+	}
+
+	if err := checkYesScope(cfg); err != nil {
+		printError(err)
 		return err
 	}
 
 	if err := docker.CheckAvailable(); err != nil {
-		fmt.Print(ui.RenderError(err.Error()))
+		printError(err)
 		return err
 	}
 
-	fmt.Print(ui.RenderHeader())
+	printHeader()
 
+	filtered := sweep.NewFiltered()
 	var containers []sweep.ContainerResource
-	if err := ui.RunWithSpinner("Analyzing containers...", func() error {
-		var err error
-		containers, err = sweep.AnalyzeContainersWithConfig(cfg)
-		return err
+	if err := analyze(context.Background(), func(ctx context.Context) error {
+		return ui.RunWithSpinner("Analyzing containers...", func() error {
+			var err error
+			containers, err = sweep.AnalyzeContainersContext(ctx, cfg, filtered, nil)
+			return err
+		})
 	}); err != nil {
 		if err.Error() == "cancelled" {
 			return nil
 		}
-		fmt.Print(ui.RenderError(err.Error()))
+		printError(err)
 		return err
 	}
 
+	result := &sweep.Result{Containers: containers, Filtered: filtered, Sort: cfg.Sort}
+	result.LimitSuggested(cfg.MaxSuggested)
+	if csvOutput() {
+		return writeCSV(result.Resources())
+	}
+	if formatOutput() {
+		return writeFormatResult(result, flagYes)
+	}
+	if jsonOutput() {
+		return writeJSONResult(result, flagYes)
+	}
+
+	if flagShowFiltered {
+		fmt.Print(ui.RenderFiltered(filtered))
+	}
+	printProtected(result)
+
 	if len(containers) == 0 {
 		fmt.Print(ui.RenderNoResources())
 		return nil
 	}
 
-	result := &sweep.Result{Containers: containers}
-
 	var toDelete []sweep.Resource
 
 	if flagYes {
@@ -64,14 +98,14 @@ func runContainers(cmd *cobra.Command, args []string) error {
 	} else {
 		if !ui.IsTTY() {
 			err := fmt.Errorf("interactive mode requires a terminal; use --yes")
-			fmt.Print(ui.RenderError(err.Error()))
+			printError(err)
 			return err
 		}
 
 		var err error
-		toDelete, err = ui.RunPicker(result)
+		toDelete, err = ui.RunPicker(result, pickerOptions(cfg))
 		if err != nil {
-			fmt.Print(ui.RenderError(err.Error()))
+			printError(err)
 			return err
 		}
 		if toDelete == nil {
@@ -85,27 +119,17 @@ func runContainers(cmd *cobra.Command, args []string) error {
 	}
 
 	if flagDryRun {
-		fmt.Print(ui.RenderDryRun(toDelete))
+		fmt.Print(ui.RenderDryRun(toDelete, result))
 		return nil
 	}
 
-	var deleted int
-	var errors []error
-	if err := ui.RunWithSpinner("Deleting containers...", func() error {
-		deleted, errors = sweep.DeleteResources(toDelete)
+	if flagYes && !confirmYes(toDelete) {
 		return nil
-	}); err != nil {
-		if err.Error() == "cancelled" {
-			return nil
-		}
-		fmt.Print(ui.RenderError(err.Error()))
-		return err
 	}
 
-	for _, err := range errors {
-		fmt.Printf("  %s\n", ui.RenderErrorInline(err.Error()))
+	if err := deleteWithProgress("Deleting containers...", toDelete); err != nil {
+		printError(err)
+		return err
 	}
-
-	fmt.Print(ui.RenderSummary(deleted, len(toDelete)))
 	return nil
 }
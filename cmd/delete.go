@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/midnattsol/docker-sweep/internal/sweep"
+	"github.com/midnattsol/docker-sweep/internal/ui"
+)
+
+// interrupted is set when an interrupt stopped an analysis, or a deletion
+// before every resource was attempted
+var interrupted bool
+
+// deletionContext returns a context cancelled on SIGINT or SIGTERM. A
+// deletion run with it stops starting removals instead of dying midway.
+func deletionContext() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+}
+
+// analyze runs fn with a context cancelled on SIGINT or SIGTERM, or when
+// parent is done, so an interrupt stops the docker calls of an analysis in
+// flight. An interrupted analysis returns the "cancelled" error callers exit
+// quietly on.
+func analyze(parent context.Context, fn func(ctx context.Context) error) error {
+	ctx, stop := signal.NotifyContext(parent, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	err := fn(ctx)
+	if ctx.Err() != nil {
+		interrupted = true
+		return fmt.Errorf("cancelled")
+	}
+	return err
+}
+
+// tallyDeleteErrors separates the resources an interrupt skipped from the
+// ones that failed, and records both for the exit code
+func tallyDeleteErrors(errs []error) (failed []error, skipped int) {
+	for _, err := range errs {
+		if errors.Is(err, sweep.ErrInterrupted) {
+			skipped++
+			continue
+		}
+		failed = append(failed, err)
+	}
+	failedDeletions += len(failed)
+	if skipped > 0 {
+		interrupted = true
+	}
+	return failed, skipped
+}
+
+// deleteWithProgress deletes resources behind a progress bar, then prints
+// the failures (or opens the results screen with --results) and a summary.
+// Ctrl+C, SIGINT or SIGTERM lets the removals in flight finish and the
+// summary covers what was deleted so far.
+func deleteWithProgress(message string, resources []sweep.Resource) error {
+	ctx, stop := deletionContext()
+	defer stop()
+
+	var deleted int
+	var errs []error
+	if err := ui.RunWithProgress(ctx, message, len(resources), func(ctx context.Context, progress func(done, total int)) error {
+		deleted, errs = deleteResources(ctx, resources, deleteProgress(progress))
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	failed, skipped := tallyDeleteErrors(errs)
+	if showResults() {
+		if err := ui.RunResults(resources, errs); err != nil {
+			return err
+		}
+	} else {
+		printDeleteErrors(failed)
+	}
+
+	fmt.Print(ui.RenderSummary(deleted, len(resources)))
+	if skipped > 0 {
+		fmt.Print(ui.RenderInterrupted(skipped))
+	}
+	return nil
+}
+
+// showResults reports whether --results can open the results screen, which
+// needs a terminal for both output and keys
+func showResults() bool {
+	return flagResults && !flagQuiet && ui.IsTTY() && ui.IsInputTTY()
+}
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/midnattsol/docker-sweep/internal/docker"
+	"github.com/midnattsol/docker-sweep/internal/sweep"
+	"github.com/midnattsol/docker-sweep/internal/ui"
+)
+
+func NewDfCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "df",
+		Short: "Summarize what a sweep would reclaim",
+		Long: `Analyze resources like a sweep would and summarize, per type, how many are
+suggested, unused, in use and protected, with the space deleting the suggested ones
+frees. Nothing is deleted. Filters like --older-than and the scope flags apply.
+
+Examples:
+  docker sweep df
+  docker sweep df --older-than 30d
+  docker sweep df -i -o json
+  docker sweep df -o tsv | awk '$1 == "total" && $3 > 10e9'`,
+		Args: cobra.NoArgs,
+		RunE: runDf,
+	}
+
+	return cmd
+}
+
+// dfJSON is the JSON output of df
+type dfJSON struct {
+	Types       []sweep.TypeUsage `json:"types"`
+	Reclaimable int64             `json:"reclaimable"`
+}
+
+func runDf(cmd *cobra.Command, args []string) error {
+	selectedTypes := flagContainers || flagImages || flagVolumes || flagNetworks
+	include := map[sweep.ResourceType]bool{
+		sweep.TypeContainer: flagContainers || !selectedTypes,
+		sweep.TypeImage:     flagImages || !selectedTypes,
+		sweep.TypeVolume:    flagVolumes || !selectedTypes,
+		sweep.TypeNetwork:   flagNetworks || !selectedTypes,
+	}
+
+	cfg, err := buildConfig(cmd)
+	if err != nil {
+		printError(err)
+		return err
+	}
+
+	if err := docker.CheckAvailable(); err != nil {
+		printError(err)
+		return err
+	}
+
+	printHeader()
+
+	result, err := analyzeRootResources(context.Background(), cfg,
+		include[sweep.TypeContainer], include[sweep.TypeImage], include[sweep.TypeVolume], include[sweep.TypeNetwork])
+	if err != nil {
+		if err.Error() == "cancelled" {
+			return nil
+		}
+		printError(err)
+		return err
+	}
+
+	var usage []sweep.TypeUsage
+	var reclaimable int64
+	for _, u := range result.Usage() {
+		if include[u.Type] {
+			usage = append(usage, u)
+			reclaimable += u.Suggested.Size
+		}
+	}
+
+	if jsonOutput() {
+		return writeJSON(dfJSON{Types: usage, Reclaimable: reclaimable})
+	}
+	if tsvOutput() {
+		writeDfTSV(usage)
+		return nil
+	}
+
+	fmt.Print(ui.RenderUsage(usage))
+	return nil
+}
+
+// writeDfTSV prints the summary as tab-separated rows with sizes in bytes,
+// ending with a "total" row. The in-use columns come last so scripts written
+// against the earlier columns keep working.
+func writeDfTSV(usage []sweep.TypeUsage) {
+	fmt.Println("type\tsuggested\tsuggested_bytes\tunused\tunused_bytes\tprotected\tprotected_bytes\tin_use\tin_use_bytes")
+
+	var total sweep.TypeUsage
+	row := func(name string, u sweep.TypeUsage) {
+		fmt.Printf("%s\t%d\t%d\t%d\t%d\t%d\t%d\t%d\t%d\n", name,
+			u.Suggested.Count, u.Suggested.Size, u.Unused.Count, u.Unused.Size, u.Protected.Count, u.Protected.Size,
+			u.InUse.Count, u.InUse.Size)
+	}
+	for _, u := range usage {
+		row(string(u.Type), u)
+		total.Suggested.Count += u.Suggested.Count
+		total.Suggested.Size += u.Suggested.Size
+		total.Unused.Count += u.Unused.Count
+		total.Unused.Size += u.Unused.Size
+		total.Protected.Count += u.Protected.Count
+		total.Protected.Size += u.Protected.Size
+		total.InUse.Count += u.InUse.Count
+		total.InUse.Size += u.InUse.Size
+	}
+	row("total", total)
+}
@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/midnattsol/docker-sweep/internal/docker"
+	"github.com/midnattsol/docker-sweep/internal/ui"
+)
+
+// errDoctorFailed is returned when a check failed; the report already says which
+var errDoctorFailed = errors.New("doctor found problems")
+
+func NewDoctorCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "doctor",
+		Short: "Diagnose the runtime, daemon connection and permissions",
+		Long: `Report the detected runtime and version, whether the daemon is reachable,
+whether the current user can list and remove resources, how many resources
+of each type exist, and any output lines that could not be parsed. Nothing
+is removed. Include the output when filing a bug report.
+
+Exits with status 1 when any check fails.
+
+Examples:
+  docker sweep doctor
+  docker sweep doctor --output json`,
+		Args:          cobra.NoArgs,
+		SilenceErrors: true,
+		RunE:          runDoctor,
+	}
+}
+
+// doctorReport is the JSON output of doctor
+type doctorReport struct {
+	Runtime   string         `json:"runtime"`
+	Client    string         `json:"client,omitempty"`
+	Server    string         `json:"server,omitempty"`
+	Endpoint  string         `json:"endpoint,omitempty"`
+	Reachable bool           `json:"reachable"`
+	CanList   bool           `json:"canList"`
+	CanRemove bool           `json:"canRemove"`
+	Counts    map[string]int `json:"counts"`
+	Warnings  []string       `json:"warnings"`
+	Errors    []string       `json:"errors"`
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	report := doctorReport{Counts: map[string]int{}, Warnings: []string{}, Errors: []string{}}
+
+	if err := docker.CheckAvailable(); err != nil {
+		report.Errors = append(report.Errors, err.Error())
+	} else {
+		report.Reachable = true
+		report.Endpoint = docker.Endpoint()
+	}
+	version := docker.RuntimeVersion()
+	report.Runtime, report.Client, report.Server = version.Runtime, version.Client, version.Server
+
+	if report.Reachable {
+		ctx := context.Background()
+		report.CanList = true
+		count := func(resourceType string, n int, err error) {
+			if err != nil && !docker.IsPartialJSON(err) {
+				report.CanList = false
+				report.Errors = append(report.Errors, fmt.Sprintf("listing %ss: %v", resourceType, err))
+				return
+			}
+			if err != nil {
+				report.Warnings = append(report.Warnings, fmt.Sprintf("listing %ss: %v", resourceType, err))
+			}
+			report.Counts[resourceType] = n
+		}
+		containers, err := docker.ListContainers(ctx)
+		count("container", len(containers), err)
+		images, err := docker.ListImages(ctx)
+		count("image", len(images), err)
+		volumes, err := docker.ListVolumes(ctx)
+		count("volume", len(volumes), err)
+		networks, err := docker.ListNetworks(ctx)
+		count("network", len(networks), err)
+
+		if err := docker.ProbeRemove(); err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("removing: %v", err))
+		} else {
+			report.CanRemove = true
+		}
+	}
+
+	if jsonOutput() {
+		if err := writeJSON(report); err != nil {
+			return err
+		}
+	} else {
+		printDoctor(report)
+	}
+
+	if len(report.Errors) > 0 {
+		return errDoctorFailed
+	}
+	return nil
+}
+
+// printDoctor prints one line per check, then the warnings and errors
+func printDoctor(r doctorReport) {
+	mark := func(ok bool) string {
+		if ok {
+			return ui.CheckStyle.Render()
+		}
+		return ui.CrossStyle.Render()
+	}
+
+	runtime := docker.VersionInfo{Runtime: r.Runtime, Server: r.Server}.String()
+	if r.Client != "" && r.Client != r.Server {
+		runtime += " (client " + r.Client + ")"
+	}
+	daemon := "unreachable"
+	if r.Reachable {
+		daemon = "reachable at " + r.Endpoint
+	}
+	list, remove := "not checked", "not checked"
+	if r.Reachable {
+		list, remove = "denied", "denied"
+		if r.CanList {
+			list = fmt.Sprintf("%d containers, %d images, %d volumes, %d networks",
+				r.Counts["container"], r.Counts["image"], r.Counts["volume"], r.Counts["network"])
+		}
+		if r.CanRemove {
+			remove = "allowed"
+		}
+	}
+
+	fmt.Println()
+	fmt.Printf("  %s %s %s\n", ui.CheckStyle.Render(), ui.BoldStyle.Render("Runtime: "), runtime)
+	fmt.Printf("  %s %s %s\n", mark(r.Reachable), ui.BoldStyle.Render("Daemon:  "), daemon)
+	fmt.Printf("  %s %s %s\n", mark(r.CanList), ui.BoldStyle.Render("List:    "), list)
+	fmt.Printf("  %s %s %s\n", mark(r.CanRemove), ui.BoldStyle.Render("Remove:  "), remove)
+	for _, w := range r.Warnings {
+		fmt.Printf("  %s %s\n", ui.WarningStyle.Render("●"), ui.WarningStyle.Render(w))
+	}
+	for _, e := range r.Errors {
+		fmt.Printf("  %s %s\n", ui.CrossStyle.Render(), ui.ErrorStyle.Render(e))
+	}
+	fmt.Println()
+}
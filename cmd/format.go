@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"text/template"
+	"time"
+
+	"github.com/midnattsol/docker-sweep/internal/sweep"
+	"github.com/midnattsol/docker-sweep/internal/ui"
+)
+
+var (
+	flagFormat     string
+	formatTemplate *template.Template // parsed --format, nil without it
+)
+
+// formatFields is the flat view of a resource that --format templates render
+type formatFields struct {
+	Name           string
+	Type           string
+	ID             string
+	Size           string // Human-readable like docker prints it, empty when unknown
+	SizeBytes      int64
+	Category       string
+	ComposeProject string
+	ProtectReason  string
+	Created        string // RFC3339, empty when unknown
+}
+
+func newFormatFields(r sweep.Resource) formatFields {
+	f := formatFields{
+		Name:           r.DisplayName(),
+		Type:           string(r.Type()),
+		ID:             r.ID(),
+		SizeBytes:      r.Size(),
+		Category:       string(r.Category()),
+		ComposeProject: sweep.GetComposeProject(r),
+		ProtectReason:  sweep.GetProtectReason(r),
+	}
+	if f.SizeBytes > 0 {
+		f.Size = ui.FormatSize(f.SizeBytes)
+	}
+	if t := sweep.GetCreatedAt(r); !t.IsZero() {
+		f.Created = t.UTC().Format(time.RFC3339)
+	}
+	return f
+}
+
+// parseFormatFlag compiles --format so template errors surface before any
+// analysis or deletion
+func parseFormatFlag() error {
+	if flagFormat == "" {
+		return nil
+	}
+	tmpl, err := template.New("format").Option("missingkey=error").Parse(flagFormat)
+	if err != nil {
+		return fmt.Errorf("invalid --format template: %w", err)
+	}
+	// Render a sample so unknown fields fail now, not halfway through a sweep
+	if err := tmpl.Execute(io.Discard, formatFields{}); err != nil {
+		return fmt.Errorf("invalid --format template: %w", err)
+	}
+	formatTemplate = tmpl
+	return nil
+}
+
+func formatOutput() bool {
+	return formatTemplate != nil
+}
+
+// writeFormat renders each resource through the --format template, one per line
+func writeFormat(resources []sweep.Resource) error {
+	for _, r := range resources {
+		if err := formatTemplate.Execute(os.Stdout, newFormatFields(r)); err != nil {
+			return err
+		}
+		fmt.Println()
+	}
+	return nil
+}
+
+// writeFormatResult renders the analysis, or deletes the suggested resources
+// and renders those deleted when yes is set. With --dry-run it renders the
+// suggested resources.
+func writeFormatResult(result *sweep.Result, yes bool) error {
+	toDelete := result.Suggested()
+	if flagDryRun {
+		return writeFormat(toDelete)
+	}
+
+	if !yes {
+		return writeFormat(result.Resources())
+	}
+
+	ctx, stop := deletionContext()
+	defer stop()
+	_, errs := deleteResources(ctx, toDelete, nil)
+	tallyDeleteErrors(errs)
+
+	failed := make(map[string]bool)
+	for _, err := range errs {
+		var de *sweep.DeleteError
+		if errors.As(err, &de) {
+			failed[string(de.Resource.Type())+"/"+de.Resource.ID()] = true
+		}
+		fmt.Fprintf(os.Stderr, "error: %s\n", err)
+	}
+
+	var deleted []sweep.Resource
+	for _, r := range toDelete {
+		if !failed[string(r.Type())+"/"+r.ID()] {
+			deleted = append(deleted, r)
+		}
+	}
+	return writeFormat(deleted)
+}
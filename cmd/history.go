@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/midnattsol/docker-sweep/internal/history"
+	"github.com/midnattsol/docker-sweep/internal/sweep"
+	"github.com/midnattsol/docker-sweep/internal/ui"
+)
+
+var (
+	flagNoHistory    bool
+	flagHistoryLimit int
+)
+
+func NewHistoryCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "Show recently deleted resources",
+		Long: `Show the resources deleted by previous sweeps, newest first.
+
+Every deletion is appended to $XDG_STATE_HOME/docker-sweep/history.jsonl
+(~/.local/state/docker-sweep/history.jsonl by default). Pass --no-history
+to a sweep to leave it out.`,
+		Args: cobra.NoArgs,
+		RunE: runHistory,
+	}
+
+	cmd.Flags().IntVar(&flagHistoryLimit, "limit", 20, "Number of entries to show (0 shows all)")
+
+	return cmd
+}
+
+func runHistory(cmd *cobra.Command, args []string) error {
+	entries, err := history.Read()
+	if err != nil {
+		err = fmt.Errorf("failed to read history: %w", err)
+		printError(err)
+		return err
+	}
+
+	// Newest first
+	for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+		entries[i], entries[j] = entries[j], entries[i]
+	}
+	if flagHistoryLimit > 0 && len(entries) > flagHistoryLimit {
+		entries = entries[:flagHistoryLimit]
+	}
+
+	if jsonOutput() {
+		if entries == nil {
+			entries = []history.Entry{}
+		}
+		return writeJSON(entries)
+	}
+
+	if len(entries) == 0 {
+		fmt.Println("No deletions recorded yet")
+		return nil
+	}
+
+	for _, e := range entries {
+		size := ""
+		if e.Size > 0 {
+			size = ui.FormatSize(e.Size)
+		}
+		fmt.Printf("%s  %-9s  %-30s  %s\n", e.Time.Local().Format(time.DateTime), e.Type, e.Name, size)
+	}
+	return nil
+}
+
+// deleteResources deletes resources and records the ones that were removed
+// in the history file. A history failure is reported but never fails the run.
+func deleteResources(ctx context.Context, resources []sweep.Resource, fn sweep.ProgressFunc) (int, []error) {
+	deleted, errs := sweep.DeleteResourcesWithJobs(ctx, resources, flagJobs, fn)
+	if flagNoHistory || deleted == 0 {
+		return deleted, errs
+	}
+
+	failed := make(map[string]bool, len(errs))
+	for _, err := range errs {
+		var de *sweep.DeleteError
+		if errors.As(err, &de) {
+			failed[string(de.Resource.Type())+"/"+de.Resource.ID()] = true
+		}
+	}
+
+	now := time.Now().UTC()
+	var entries []history.Entry
+	for _, r := range sweep.Dedupe(resources) {
+		if failed[string(r.Type())+"/"+r.ID()] {
+			continue
+		}
+		entries = append(entries, history.Entry{
+			Time: now,
+			Type: string(r.Type()),
+			ID:   r.ID(),
+			Name: r.DisplayName(),
+			Size: r.Size(),
+		})
+	}
+
+	if err := history.Append(entries); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to write history: %v\n", err)
+	}
+	return deleted, errs
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/spf13/cobra"
@@ -18,52 +19,90 @@ func NewImagesCmd() *cobra.Command {
 		RunE:    runImages,
 	}
 
+	cmd.Flags().StringVar(&flagRepository, "repository", "", "Only images of this repository (exact or glob, e.g. registry.local/myapp); dangling images need --dangling")
 	cmd.Flags().StringVar(&flagMinSize, "min-size", "", "Only images larger than size (e.g., 100MB, 1GB)")
+	cmd.Flags().BoolVar(&flagDedupeLayers, "dedupe-layers", false, "Count image layers shared with kept images once in the space to recover (slow: inspects every image)")
+	cmd.Flags().StringVar(&flagMaxSize, "max-size", "", "Only images no larger than size (e.g., 50MB)")
 	cmd.Flags().BoolVar(&flagDangling, "dangling", false, "Only dangling images")
+	cmd.Flags().BoolVar(&flagUntagged, "untagged", false, "Only images without any tag (dangling or pulled by digest), suggested for deletion")
 	cmd.Flags().BoolVar(&flagNoDangling, "no-dangling", false, "Exclude dangling images")
+	cmd.Flags().StringArrayVar(&flagProtectTags, "protect-tag", nil, "Protect images whose tag matches a glob (repeatable; default latest, \"\" disables)")
+	cmd.Flags().IntVar(&flagKeepLast, "keep-last", 0, "Suggest tagged images beyond the newest N per repository")
+	cmd.Flags().BoolVar(&flagPruneUntaggedRemote, "prune-untagged-remote", false, "Suggest tagged images whose tag no longer exists in their registry")
+	cmd.Flags().BoolVar(&flagLayerAge, "prune-by-digest-age", false, "Date images by their newest history layer instead of Created for age filters (slow: reads each image's history)")
+	cmd.Flags().BoolVar(&flagProtectIfChildRunning, "protect-if-child-running", true, "Protect images that are parents of in-use images")
+	cmd.Flags().BoolVar(&flagLeavesOnly, "leaves-only", false, "Only suggest leaf images: protect every image another local image was built on")
+
+	_ = cmd.RegisterFlagCompletionFunc("min-size", completeSizes)
 
 	return cmd
 }
 
 func runImages(cmd *cobra.Command, args []string) error {
 	if err := validateTypeSpecificFlags(false, true, false, false); err != nil {
-		fmt.Print(ui.RenderError(err.Error()))
+		printError(err)
 		return err
 	}
 
-	cfg, err := buildConfig()
+	cfg, err := buildConfig(cmd)
 	if err != nil {
-		fmt.Print(ui.RenderError(err.Error()))
+		printError(err)
 		return err
 	}
 
-	if err := docker.CheckAvailable(); err != nil {
-		fmt.Print(ui.RenderError(err.Error()))
+	if err := checkYesScope(cfg); err != nil {
+		printError(err)
 		return err
 	}
 
-	fmt.Print(ui.RenderHeader())
-
-	var images []sweep.ImageResource
-	if err := ui.RunWithSpinner("Analyzing images...", func() error {
-		var err error
-		images, err = sweep.AnalyzeImagesWithConfig(cfg)
+	if err := docker.CheckAvailable(); err != nil {
+		printError(err)
 		return err
+	}
+
+	printHeader()
+
+	filtered := sweep.NewFiltered()
+	result := &sweep.Result{Filtered: filtered, Sort: cfg.Sort}
+	if err := analyze(context.Background(), func(ctx context.Context) error {
+		return ui.RunWithSpinner("Analyzing images...", func() error {
+			var err error
+			result.Images, err = sweep.AnalyzeImagesContext(ctx, cfg, filtered, nil)
+			if err == nil && flagDedupeLayers {
+				// Without the index, sizes fall back to the per-image sum
+				_ = result.IndexLayersContext(ctx)
+			}
+			return err
+		})
 	}); err != nil {
 		if err.Error() == "cancelled" {
 			return nil
 		}
-		fmt.Print(ui.RenderError(err.Error()))
+		printError(err)
 		return err
 	}
+	result.LimitSuggested(cfg.MaxSuggested)
+
+	if csvOutput() {
+		return writeCSV(result.Resources())
+	}
+	if formatOutput() {
+		return writeFormatResult(result, flagYes)
+	}
+	if jsonOutput() {
+		return writeJSONResult(result, flagYes)
+	}
+
+	if flagShowFiltered {
+		fmt.Print(ui.RenderFiltered(filtered))
+	}
+	printProtected(result)
 
-	if len(images) == 0 {
+	if len(result.Images) == 0 {
 		fmt.Print(ui.RenderNoResources())
 		return nil
 	}
 
-	result := &sweep.Result{Images: images}
-
 	var toDelete []sweep.Resource
 
 	if flagYes {
@@ -71,14 +110,14 @@ func runImages(cmd *cobra.Command, args []string) error {
 	} else {
 		if !ui.IsTTY() {
 			err := fmt.Errorf("interactive mode requires a terminal; use --yes")
-			fmt.Print(ui.RenderError(err.Error()))
+			printError(err)
 			return err
 		}
 
 		var err error
-		toDelete, err = ui.RunPicker(result)
+		toDelete, err = ui.RunPicker(result, pickerOptions(cfg))
 		if err != nil {
-			fmt.Print(ui.RenderError(err.Error()))
+			printError(err)
 			return err
 		}
 		if toDelete == nil {
@@ -92,27 +131,17 @@ func runImages(cmd *cobra.Command, args []string) error {
 	}
 
 	if flagDryRun {
-		fmt.Print(ui.RenderDryRun(toDelete))
+		fmt.Print(ui.RenderDryRun(toDelete, result))
 		return nil
 	}
 
-	var deleted int
-	var errors []error
-	if err := ui.RunWithSpinner("Deleting images...", func() error {
-		deleted, errors = sweep.DeleteResources(toDelete)
+	if flagYes && !confirmYes(toDelete) {
 		return nil
-	}); err != nil {
-		if err.Error() == "cancelled" {
-			return nil
-		}
-		fmt.Print(ui.RenderError(err.Error()))
-		return err
 	}
 
-	for _, err := range errors {
-		fmt.Printf("  %s\n", ui.RenderErrorInline(err.Error()))
+	if err := deleteWithProgress("Deleting images...", toDelete); err != nil {
+		printError(err)
+		return err
 	}
-
-	fmt.Print(ui.RenderSummary(deleted, len(toDelete)))
 	return nil
 }
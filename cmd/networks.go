@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/spf13/cobra"
@@ -18,43 +19,70 @@ func NewNetworksCmd() *cobra.Command {
 		RunE:    runNetworks,
 	}
 
+	cmd.Flags().BoolVar(&flagOrphans, "orphans", false, "Suggest volumes and networks of Compose projects with no containers left")
+
 	return cmd
 }
 
 func runNetworks(cmd *cobra.Command, args []string) error {
-	cfg, err := buildConfig()
+	cfg, err := buildConfig(cmd)
 	if err != nil {
-		fmt.Print(ui.RenderError(err.Error()))
+		printError(err)
 		return err
 	}
 
-	if err := docker.CheckAvailable(); err != nil {
-		fmt.Print(ui.RenderError(err.Error()))
+	if err := checkYesScope(cfg); err != nil {
+		printError(err)
 		return err
 	}
 
-	fmt.Print(ui.RenderHeader())
-
-	var networks []sweep.NetworkResource
-	if err := ui.RunWithSpinner("Analyzing networks...", func() error {
-		var err error
-		networks, err = sweep.AnalyzeNetworksWithConfig(cfg)
+	if err := docker.CheckAvailable(); err != nil {
+		printError(err)
 		return err
+	}
+
+	printHeader()
+
+	filtered := sweep.NewFiltered()
+	result := &sweep.Result{Filtered: filtered, Sort: cfg.Sort}
+	if err := analyze(context.Background(), func(ctx context.Context) error {
+		return ui.RunWithSpinner("Analyzing networks...", func() error {
+			var err error
+			result.Networks, err = sweep.AnalyzeNetworksContext(ctx, cfg, filtered, nil)
+			if err != nil || !cfg.Orphans {
+				return err
+			}
+			return sweep.MarkOrphansContext(ctx, result)
+		})
 	}); err != nil {
 		if err.Error() == "cancelled" {
 			return nil
 		}
-		fmt.Print(ui.RenderError(err.Error()))
+		printError(err)
 		return err
 	}
+	result.LimitSuggested(cfg.MaxSuggested)
+
+	if csvOutput() {
+		return writeCSV(result.Resources())
+	}
+	if formatOutput() {
+		return writeFormatResult(result, flagYes)
+	}
+	if jsonOutput() {
+		return writeJSONResult(result, flagYes)
+	}
 
-	if len(networks) == 0 {
+	if flagShowFiltered {
+		fmt.Print(ui.RenderFiltered(filtered))
+	}
+	printProtected(result)
+
+	if len(result.Networks) == 0 {
 		fmt.Print(ui.RenderNoResources())
 		return nil
 	}
 
-	result := &sweep.Result{Networks: networks}
-
 	var toDelete []sweep.Resource
 
 	if flagYes {
@@ -62,14 +90,14 @@ func runNetworks(cmd *cobra.Command, args []string) error {
 	} else {
 		if !ui.IsTTY() {
 			err := fmt.Errorf("interactive mode requires a terminal; use --yes")
-			fmt.Print(ui.RenderError(err.Error()))
+			printError(err)
 			return err
 		}
 
 		var err error
-		toDelete, err = ui.RunPicker(result)
+		toDelete, err = ui.RunPicker(result, pickerOptions(cfg))
 		if err != nil {
-			fmt.Print(ui.RenderError(err.Error()))
+			printError(err)
 			return err
 		}
 		if toDelete == nil {
@@ -83,27 +111,17 @@ func runNetworks(cmd *cobra.Command, args []string) error {
 	}
 
 	if flagDryRun {
-		fmt.Print(ui.RenderDryRun(toDelete))
+		fmt.Print(ui.RenderDryRun(toDelete, result))
 		return nil
 	}
 
-	var deleted int
-	var errors []error
-	if err := ui.RunWithSpinner("Deleting networks...", func() error {
-		deleted, errors = sweep.DeleteResources(toDelete)
+	if flagYes && !confirmYes(toDelete) {
 		return nil
-	}); err != nil {
-		if err.Error() == "cancelled" {
-			return nil
-		}
-		fmt.Print(ui.RenderError(err.Error()))
-		return err
 	}
 
-	for _, err := range errors {
-		fmt.Printf("  %s\n", ui.RenderErrorInline(err.Error()))
+	if err := deleteWithProgress("Deleting networks...", toDelete); err != nil {
+		printError(err)
+		return err
 	}
-
-	fmt.Print(ui.RenderSummary(deleted, len(toDelete)))
 	return nil
 }
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"github.com/midnattsol/docker-sweep/internal/history"
+	"github.com/midnattsol/docker-sweep/internal/ui"
+	"github.com/midnattsol/docker-sweep/internal/update"
+)
+
+// noUpdateCheckEnv disables the background update notice when set
+const noUpdateCheckEnv = "DOCKER_SWEEP_NO_UPDATE_CHECK"
+
+const (
+	updateCheckTimeout = 3 * time.Second        // bound on the background check itself
+	updateNoticeWait   = 200 * time.Millisecond // how long a finished sweep waits for it
+)
+
+// noticeCommands are the sweeping commands that end with an update notice
+var noticeCommands = map[string]bool{
+	"docker-sweep": true,
+	"containers":   true,
+	"images":       true,
+	"volumes":      true,
+	"networks":     true,
+	"prune":        true,
+	"apply":        true,
+}
+
+// updateNotice receives the result of the background update check, if started
+var updateNotice chan string
+
+// startUpdateCheck looks for a newer release in the background while cmd runs.
+// It is skipped for scripted output and non-interactive stderr.
+func startUpdateCheck(cmd *cobra.Command) {
+	if os.Getenv(noUpdateCheckEnv) != "" || !noticeCommands[cmd.Name()] || flagVersion || flagQuiet ||
+		machineOutput() || update.CurrentVersion == "dev" || !term.IsTerminal(int(os.Stderr.Fd())) {
+		return
+	}
+
+	updateNotice = make(chan string, 1)
+	go func() {
+		dir, err := history.StateDir()
+		if err != nil {
+			updateNotice <- ""
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), updateCheckTimeout)
+		defer cancel()
+		updateNotice <- update.CheckThrottled(ctx, filepath.Join(dir, "update-check.json"))
+	}()
+}
+
+// printUpdateNotice prints the notice if the background check found a newer
+// release. A check that has not finished yet is abandoned.
+func printUpdateNotice() {
+	if updateNotice == nil {
+		return
+	}
+	select {
+	case tag := <-updateNotice:
+		if tag != "" {
+			fmt.Fprint(os.Stderr, ui.RenderUpdateNotice(tag))
+		}
+	case <-time.After(updateNoticeWait):
+	}
+}
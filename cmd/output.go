@@ -0,0 +1,208 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/midnattsol/docker-sweep/internal/sweep"
+	"github.com/midnattsol/docker-sweep/internal/ui"
+)
+
+// Output formats for --output
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputCSV   = "csv"
+	outputTSV   = "tsv"
+)
+
+var flagOutput string
+
+// analysisJSON is the document printed by --output json without --yes
+type analysisJSON struct {
+	Resources []sweep.Record `json:"resources"`
+}
+
+// errorJSON is the document printed by --output json on fatal errors
+type errorJSON struct {
+	Error string `json:"error"`
+}
+
+// listCommands are the commands whose analysis --output csv and --format can list
+var listCommands = map[string]bool{"containers": true, "images": true, "volumes": true, "networks": true}
+
+// applyOutputFlag validates --output and configures the ui for it
+func applyOutputFlag(cmd *cobra.Command) error {
+	var err error
+	switch flagOutput {
+	case outputTable, outputJSON:
+	case outputCSV:
+		switch {
+		case cmd != cmd.Root() && !listCommands[cmd.Name()]:
+			err = fmt.Errorf("--output csv is not supported by %s", cmd.CommandPath())
+		case flagYes || flagGC:
+			err = fmt.Errorf("--output csv only exports the analysis; it cannot be combined with --yes or --gc")
+		}
+	case outputTSV:
+		if cmd.Name() != "df" {
+			err = fmt.Errorf("--output tsv is only supported by df")
+		}
+	default:
+		err = fmt.Errorf("invalid --output value %q (expected table, json, csv or tsv)", flagOutput)
+	}
+	if err == nil && flagFormat != "" {
+		switch {
+		case flagOutput != outputTable:
+			err = fmt.Errorf("--format and --output %s are mutually exclusive", flagOutput)
+		case cmd != cmd.Root() && !listCommands[cmd.Name()]:
+			err = fmt.Errorf("--format is not supported by %s", cmd.CommandPath())
+		default:
+			err = parseFormatFlag()
+		}
+	}
+	if err != nil {
+		fmt.Print(ui.RenderError(err.Error()))
+		return err
+	}
+
+	ui.SetSilent(machineOutput())
+	return nil
+}
+
+func jsonOutput() bool {
+	return flagOutput == outputJSON
+}
+
+func csvOutput() bool {
+	return flagOutput == outputCSV
+}
+
+func tsvOutput() bool {
+	return flagOutput == outputTSV
+}
+
+// machineOutput reports whether output is machine-readable (json, csv, tsv or --format)
+func machineOutput() bool {
+	return jsonOutput() || csvOutput() || tsvOutput() || formatOutput()
+}
+
+// outputFlagName names the flag that selected machine-readable output, for errors
+func outputFlagName() string {
+	if formatOutput() {
+		return "--format"
+	}
+	return "--output " + flagOutput
+}
+
+// printHeader prints the header unless output is machine-readable
+func printHeader() {
+	if !machineOutput() {
+		fmt.Print(ui.RenderHeader())
+	}
+}
+
+// printError reports err in the current output format
+func printError(err error) {
+	if jsonOutput() {
+		_ = writeJSON(errorJSON{Error: err.Error()})
+		return
+	}
+	if csvOutput() || tsvOutput() || formatOutput() {
+		// Keep stdout parseable
+		fmt.Fprintf(os.Stderr, "error: %s\n", err)
+		return
+	}
+	fmt.Print(ui.RenderError(err.Error()))
+}
+
+func writeJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(v)
+}
+
+// writeJSONResult prints the analysis, or deletes the suggested resources
+// and prints the outcome when yes is set. With --dry-run it prints the
+// suggested resources as a manifest that apply can execute later.
+func writeJSONResult(result *sweep.Result, yes bool) error {
+	toDelete := result.Suggested()
+	if flagDryRun {
+		report := sweep.NewDryRunReport(toDelete)
+		report.Protected = protectedRecords(result)
+		return writeJSON(report)
+	}
+
+	if !yes {
+		return writeJSON(analysisJSON{Resources: sweep.NewRecords(result.Resources())})
+	}
+
+	ctx, stop := deletionContext()
+	defer stop()
+	_, errs := deleteResources(ctx, toDelete, nil)
+	tallyDeleteErrors(errs)
+	report := sweep.NewReport(toDelete, errs)
+	report.Protected = protectedRecords(result)
+	return writeJSON(report)
+}
+
+// printDeleteErrors lists failed deletions. JSON output never gets here: its
+// report carries the failures as error records next to the summary counts.
+func printDeleteErrors(errs []error) {
+	for _, err := range errs {
+		fmt.Printf("  %s\n", ui.RenderErrorInline(err.Error()))
+	}
+}
+
+// csvHeader is the column order of --output csv
+var csvHeader = []string{"type", "name", "id", "category", "size_bytes", "created", "compose_project", "protect_reason"}
+
+// writeCSV prints resources as CSV. Unknown sizes and creation times are left
+// empty rather than 0, so spreadsheets do not take them for real values.
+func writeCSV(resources []sweep.Resource) error {
+	w := csv.NewWriter(os.Stdout)
+	_ = w.Write(csvHeader)
+	for _, r := range resources {
+		var size, created string
+		if s := r.Size(); s > 0 {
+			size = strconv.FormatInt(s, 10)
+		}
+		if t := sweep.GetCreatedAt(r); !t.IsZero() {
+			created = t.UTC().Format(time.RFC3339)
+		}
+		_ = w.Write([]string{
+			string(r.Type()),
+			r.DisplayName(),
+			r.ID(),
+			string(r.Category()),
+			size,
+			created,
+			sweep.GetComposeProject(r),
+			sweep.GetProtectReason(r),
+		})
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// protectedRecords returns the protected resources for a report with --show-protected
+func protectedRecords(result *sweep.Result) []sweep.Record {
+	if !flagShowProtected {
+		return nil
+	}
+	return sweep.NewRecords(result.Protected())
+}
+
+// printProtected lists protected resources and why they are kept for --yes,
+// --gc and --dry-run runs; the picker also shows the reasons inline
+func printProtected(result *sweep.Result) {
+	if flagShowProtected && !machineOutput() && (flagYes || flagGC || flagDryRun) {
+		fmt.Print(ui.RenderProtected(result.Protected()))
+	}
+}
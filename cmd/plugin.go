@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/midnattsol/docker-sweep/internal/ui"
+	"github.com/midnattsol/docker-sweep/internal/update"
+)
+
+func NewInstallPluginCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "install-plugin",
+		Short: "Install this binary as a Docker CLI plugin (docker sweep)",
+		Long: `Copy the running binary to ~/.docker/cli-plugins/docker-sweep ($DOCKER_CONFIG is
+honored) so it runs as ` + "`docker sweep`" + `. Running it from the plugin itself does nothing.`,
+		Args: cobra.NoArgs,
+		RunE: runInstallPlugin,
+	}
+}
+
+func NewUninstallPluginCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "uninstall-plugin",
+		Short: "Remove the Docker CLI plugin installed by install-plugin",
+		Args:  cobra.NoArgs,
+		RunE:  runUninstallPlugin,
+	}
+}
+
+func runInstallPlugin(cmd *cobra.Command, args []string) error {
+	path, installed, err := update.InstallPlugin()
+	if err != nil {
+		fmt.Print(ui.RenderError(err.Error()))
+		return err
+	}
+
+	if !installed {
+		fmt.Printf("\n  %s Already running as the plugin: %s\n\n", ui.CheckStyle.Render(), ui.BoldStyle.Render(path))
+		return nil
+	}
+	fmt.Printf("\n  %s Installed to %s; run %s\n\n", ui.CheckStyle.Render(), ui.BoldStyle.Render(path), ui.BoldStyle.Render("docker sweep"))
+	return nil
+}
+
+func runUninstallPlugin(cmd *cobra.Command, args []string) error {
+	path, err := update.UninstallPlugin()
+	if errors.Is(err, update.ErrPluginNotInstalled) {
+		fmt.Printf("\n  %s Nothing to remove: %s does not exist\n\n", ui.MutedStyle.Render("●"), path)
+		return nil
+	}
+	if err != nil {
+		fmt.Print(ui.RenderError(err.Error()))
+		return err
+	}
+
+	fmt.Printf("\n  %s Removed %s\n\n", ui.CheckStyle.Render(), ui.BoldStyle.Render(path))
+	return nil
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/midnattsol/docker-sweep/internal/docker"
+	"github.com/midnattsol/docker-sweep/internal/ui"
+)
+
+var flagPruneIncludeVolumes bool
+
+func NewPruneCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Remove suggested resources without interaction",
+		Long: `Remove stopped containers, dangling images and unused networks in one shot,
+like docker system prune, while still honoring sweep.protect labels.
+
+The type flags (-c, -i, -v, -n) narrow it like everywhere else, and
+--include-volumes adds anonymous volumes to the types selected.
+
+Examples:
+  docker sweep prune                      # Containers, dangling images and networks
+  docker sweep prune --include-volumes    # Also remove anonymous volumes
+  docker sweep prune -c                   # Only stopped containers
+  docker sweep prune -c --include-volumes # Stopped containers and anonymous volumes
+  docker sweep prune --dry-run            # Show what would be removed`,
+		RunE: runPrune,
+	}
+
+	cmd.Flags().BoolVar(&flagPruneIncludeVolumes, "include-volumes", false, "Also remove unused anonymous volumes")
+
+	return cmd
+}
+
+func runPrune(cmd *cobra.Command, args []string) error {
+	selectedTypes := flagContainers || flagImages || flagVolumes || flagNetworks
+	pruneContainers := flagContainers || !selectedTypes
+	pruneImages := flagImages || !selectedTypes
+	// --include-volumes adds volumes to whatever types are selected
+	pruneVolumes := flagVolumes || flagPruneIncludeVolumes
+	pruneNetworks := flagNetworks || !selectedTypes
+
+	if err := validateTypeSpecificFlags(pruneContainers, pruneImages, pruneVolumes, pruneNetworks); err != nil {
+		printError(err)
+		return err
+	}
+
+	cfg, err := buildConfig(cmd)
+	if err != nil {
+		printError(err)
+		return err
+	}
+	// Prune always includes dangling images
+	cfg.Dangling = false
+	cfg.NoDangling = false
+
+	if err := docker.CheckAvailable(); err != nil {
+		printError(err)
+		return err
+	}
+
+	printHeader()
+
+	result, err := analyzeRootResources(context.Background(), cfg, pruneContainers, pruneImages, pruneVolumes, pruneNetworks)
+	if err != nil {
+		if err.Error() == "cancelled" {
+			return nil
+		}
+		printError(err)
+		return err
+	}
+
+	if jsonOutput() {
+		return writeJSONResult(result, true)
+	}
+
+	toDelete := result.Suggested()
+	if len(toDelete) == 0 {
+		fmt.Print(ui.RenderNoResources())
+		return nil
+	}
+
+	if flagDryRun {
+		fmt.Print(ui.RenderDryRun(toDelete, result))
+		return nil
+	}
+
+	if err := deleteWithProgress("Pruning resources...", toDelete); err != nil {
+		printError(err)
+		return err
+	}
+	return nil
+}
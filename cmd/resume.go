@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/midnattsol/docker-sweep/internal/history"
+	"github.com/midnattsol/docker-sweep/internal/sweep"
+)
+
+// resumeWindow is how long a cancelled picker selection can be resumed
+const resumeWindow = time.Hour
+
+// savedSelection is the picker selection left behind by a cancelled run
+type savedSelection struct {
+	Saved time.Time `json:"saved"`
+	Keys  []string  `json:"keys"` // sweep.RowKeys of the selected rows
+}
+
+func selectionPath() (string, error) {
+	dir, err := history.StateDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "selection.json"), nil
+}
+
+// checkResumeFlag rejects --resume where no picker opens
+func checkResumeFlag() error {
+	if flagResume && ((flagYes || flagGC) && !flagInteractive || machineOutput()) {
+		err := fmt.Errorf("--resume restores the picker selection; it cannot be combined with --yes, --gc or %s", outputFlagName())
+		printError(err)
+		return err
+	}
+	return nil
+}
+
+// loadSelection returns the row keys selected when the picker was last
+// cancelled, or nil when nothing was saved within resumeWindow
+func loadSelection() map[string]bool {
+	path, err := selectionPath()
+	if err != nil {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	var saved savedSelection
+	// Selections saved by older versions hold no keys and are dropped
+	if json.Unmarshal(data, &saved) != nil || time.Since(saved.Saved) > resumeWindow || saved.Keys == nil {
+		return nil
+	}
+
+	keys := make(map[string]bool, len(saved.Keys))
+	for _, key := range saved.Keys {
+		keys[key] = true
+	}
+	return keys
+}
+
+// rememberSelection keeps a cancelled selection for --resume and forgets it
+// once a selection is confirmed. Failures only cost the next resume.
+func rememberSelection(selected []sweep.Resource, cancelled bool) {
+	path, err := selectionPath()
+	if err != nil {
+		return
+	}
+	if !cancelled {
+		_ = os.Remove(path)
+		return
+	}
+
+	saved := savedSelection{Saved: time.Now(), Keys: make([]string, 0, len(selected))}
+	for _, r := range selected {
+		saved.Keys = append(saved.Keys, sweep.RowKeys(r)...)
+	}
+	data, err := json.Marshal(saved)
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return
+	}
+	_ = os.WriteFile(path, data, 0o600)
+}
@@ -1,8 +1,13 @@
 package cmd
 
 import (
+	"bufio"
+	"context"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -14,16 +19,76 @@ import (
 )
 
 var (
-	flagYes        bool
-	flagDryRun     bool
-	flagVersion    bool
-	flagOlderThan  string
-	flagMinSize    string
-	flagDangling   bool
-	flagNoDangling bool
-	flagGC         bool
-	flagExited     bool
-	flagAnonymous  bool
+	flagYes          bool
+	flagInteractive  bool
+	flagResume       bool
+	flagAllSuggested bool
+	flagPrompt       bool
+	flagDryRun       bool
+	flagAll          bool
+	flagVersion      bool
+	flagOlderThan    string
+	flagNewerThan    string
+	flagUntil        string
+	flagMinAge       string
+	flagGrace        string
+
+	flagContainersOlderThan string
+	flagImagesOlderThan     string
+	flagVolumesOlderThan    string
+	flagNetworksOlderThan   string
+	flagName                string
+	flagLabels              []string
+	flagExclude             []string
+	flagProtect             []string
+	flagProtectAny          bool
+	flagProtectActive       bool
+	flagProtectTags         []string
+	flagMinSize             string
+	flagMaxSize             string
+	flagRepository          string
+	flagDangling            bool
+	flagKeepLast            int
+	flagNoDangling          bool
+	flagUntagged            bool
+	flagGC                  bool
+	flagWatch               time.Duration
+	flagExited              bool
+	flagCreated             bool
+	flagAnonymous           bool
+	flagOrphans             bool
+	flagForce               bool
+
+	flagStop        bool
+	flagStopTimeout time.Duration
+
+	flagReportInterval int
+	flagJobs           int
+	flagTimeout        time.Duration
+	flagContext        string
+	flagHost           string
+	flagSocket         string
+	flagQuiet          bool
+	flagNoColor        bool
+	flagTheme          string
+	flagSort           string
+	flagMaxSuggested   int
+	flagShowFiltered   bool
+	flagShowProtected  bool
+	flagGroupBy        string
+	flagFast           bool
+	flagResults        bool
+
+	flagConfirm          bool
+	flagConfirmThreshold int
+
+	flagProtectIfChildRunning bool
+	flagLeavesOnly            bool
+	flagRespectRestart        bool
+	flagPruneUntaggedRemote   bool
+	flagLayerAge              bool
+	flagDedupeLayers          bool
+	flagVolumeSizes           bool
 
 	flagContainers bool
 	flagImages     bool
@@ -40,9 +105,51 @@ func NewRootCmd(version string) *cobra.Command {
 Suggested resources are pre-selected (stopped containers, unused volumes and
 networks). Dangling images are excluded by default from root sweeps.
 Use --dangling to target dangling images, --gc for automatic cleanup, or --yes
-to skip interaction and delete all suggested resources.
+to skip interaction and delete suggested resources (narrowed by a filter, or
+with --all-suggested for all of them).
 
-Resources with the label sweep.protect=true are never deleted.`,
+Resources with the label sweep.protect=true (or the keys given with
+--protect-label) are never deleted.`,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			docker.SetContext(flagContext)
+			if err := applyHostFlags(); err != nil {
+				printError(err)
+				return err
+			}
+			ui.SetQuiet(flagQuiet)
+			if flagNoColor || os.Getenv("NO_COLOR") != "" {
+				ui.DisableColor()
+			}
+			if err := ui.SetTheme(flagTheme); err != nil {
+				printError(err)
+				return err
+			}
+			if flagJobs < 1 {
+				err := fmt.Errorf("--jobs must be at least 1")
+				printError(err)
+				return err
+			}
+			if flagTimeout < 0 {
+				err := fmt.Errorf("--timeout must not be negative")
+				printError(err)
+				return err
+			}
+			docker.SetTimeout(flagTimeout)
+			if err := applyOutputFlag(cmd); err != nil {
+				return err
+			}
+			if err := applyInteractiveFlag(); err != nil {
+				return err
+			}
+			if err := checkResumeFlag(); err != nil {
+				return err
+			}
+			startUpdateCheck(cmd)
+			return nil
+		},
+		PersistentPostRun: func(cmd *cobra.Command, args []string) {
+			printUpdateNotice()
+		},
 		RunE:         runRoot,
 		SilenceUsage: true,
 	}
@@ -50,52 +157,220 @@ Resources with the label sweep.protect=true are never deleted.`,
 
 	// Global flags
 	cmd.PersistentFlags().BoolVarP(&flagYes, "yes", "y", false, "Skip interaction and delete all suggested resources")
+	cmd.PersistentFlags().BoolVar(&flagAll, "all", false, "Also suggest unused resources such as named volumes and tagged images (protection still wins)")
+	cmd.PersistentFlags().BoolVar(&flagAllSuggested, "all-suggested", false, "Allow --yes to delete everything suggested without a narrowing filter")
+	cmd.PersistentFlags().BoolVar(&flagPrompt, "prompt", false, "Ask for a last y/N before --yes deletes (skipped when stdin is not a terminal)")
+	cmd.PersistentFlags().BoolVar(&flagInteractive, "interactive", false, "Always open the picker, even with --yes or --gc (requires a terminal)")
+	cmd.PersistentFlags().BoolVar(&flagResume, "resume", false, "Open the picker with the selection of a run cancelled within the last hour")
 	cmd.PersistentFlags().BoolVar(&flagDryRun, "dry-run", false, "Show what would be deleted without deleting")
 	cmd.PersistentFlags().BoolVarP(&flagVersion, "version", "V", false, "Show version")
 	cmd.PersistentFlags().StringVar(&flagOlderThan, "older-than", "", "Only resources older than duration (e.g., 7d, 24h, 1w)")
+	cmd.PersistentFlags().StringVar(&flagMinAge, "min-age", "", "Protect resources younger than duration (e.g., 10m, 1h)")
+	cmd.PersistentFlags().StringVar(&flagGrace, "grace", "", "Protect Compose volumes and networks younger than duration (e.g., 30s, 2m)")
+	cmd.PersistentFlags().StringVar(&flagNewerThan, "newer-than", "", "Only resources newer than duration (e.g., 1h, 30m)")
+	cmd.PersistentFlags().StringVar(&flagUntil, "until", "", "Only resources created before a timestamp, date or duration ago (e.g., 2024-05-01T00:00:00Z, 2024-05-01, 7d)")
+	cmd.PersistentFlags().StringVar(&flagName, "name", "", "Only resources whose name matches a regular expression")
+	cmd.PersistentFlags().StringArrayVar(&flagLabels, "label", nil, "Only resources with label key or key=value (repeatable)")
+	cmd.PersistentFlags().StringArrayVar(&flagExclude, "exclude", nil, "Protect resources whose name matches a glob (repeatable)")
+	cmd.PersistentFlags().StringArrayVar(&flagProtect, "protect-label", nil, "Label key that protects resources when \"true\", replacing sweep.protect (repeatable; also DOCKER_SWEEP_PROTECT_LABEL)")
+	cmd.PersistentFlags().BoolVar(&flagProtectAny, "protect-any-value", false, "Protect resources that carry a protect label with any value, not only \"true\"")
+	cmd.PersistentFlags().BoolVar(&flagProtectActive, "protect-active-projects", false, "Protect the containers, volumes and networks of Compose projects with a running container")
 	cmd.PersistentFlags().BoolVarP(&flagContainers, "containers", "c", false, "Only include containers")
 	cmd.PersistentFlags().BoolVarP(&flagImages, "images", "i", false, "Only include images")
 	cmd.PersistentFlags().BoolVarP(&flagNetworks, "networks", "n", false, "Only include networks")
 	cmd.PersistentFlags().BoolVarP(&flagVolumes, "volumes", "v", false, "Only include volumes")
+	cmd.PersistentFlags().BoolVar(&flagForce, "force", false, "Suggest running containers and remove them with rm -f; --yes deletes them (Compose and sweep.protect still win)")
+	cmd.PersistentFlags().StringVar(&flagContext, "context", "", "Docker context (Podman connection) to clean; defaults to DOCKER_HOST or the current context")
+	cmd.PersistentFlags().StringVarP(&flagHost, "host", "H", "", "Daemon address to clean (unix://, tcp:// or ssh://), overriding DOCKER_HOST and contexts")
+	cmd.PersistentFlags().StringVar(&flagSocket, "socket", "", "Path of the daemon socket to clean, e.g. $XDG_RUNTIME_DIR/docker.sock for rootless Docker")
+	cmd.PersistentFlags().StringVar(&flagSort, "sort", "", "Order --yes deletions and json, csv or --format output by name, size (largest first), age (oldest first) or type")
+	cmd.PersistentFlags().IntVar(&flagMaxSuggested, "max-suggested", 0, "Suggest at most N resources per run, oldest first or in --sort order (0 means no limit)")
+	cmd.PersistentFlags().StringVar(&flagFormat, "format", "", "Print each resource with a Go template, e.g. '{{.Name}} {{.Size}}' (fields: Name, Type, ID, Size, SizeBytes, Category, ComposeProject, ProtectReason, Created)")
+	cmd.PersistentFlags().StringVarP(&flagOutput, "output", "o", outputTable, "Output format: table, json, csv or tsv (non-interactive except table; csv only exports the analysis, tsv is for df)")
+	cmd.PersistentFlags().BoolVarP(&flagQuiet, "quiet", "q", false, "Print only essential lines: no header, spinners or decoration")
+	cmd.PersistentFlags().BoolVar(&flagNoColor, "no-color", false, "Disable colored output (also set by NO_COLOR)")
+	cmd.PersistentFlags().StringVar(&flagTheme, "theme", ui.ThemeDefault, "Color theme: default, high-contrast (color-blind friendly) or mono (symbols instead of colors)")
+	cmd.PersistentFlags().BoolVar(&flagShowFiltered, "show-filtered", false, "Report how many resources each filter skipped")
+	cmd.PersistentFlags().BoolVar(&flagShowProtected, "show-protected", false, "Show why protected resources are kept")
+	cmd.PersistentFlags().StringVar(&flagGroupBy, "group-by", "", "Section the picker by the value of this label key (e.g. env), with counts and sizes per value")
+	cmd.PersistentFlags().BoolVar(&flagFast, "fast", false, "Skip most per-resource inspect calls and use list output (labels and creation times may be missing)")
+	cmd.PersistentFlags().BoolVar(&flagConfirm, "confirm", false, "Always confirm the selection before deleting")
+	cmd.PersistentFlags().IntVar(&flagConfirmThreshold, "confirm-threshold", 20, "Confirm the selection when more than N resources are selected (0 disables)")
+	cmd.PersistentFlags().BoolVar(&flagResults, "results", false, "After deleting, open a scrollable screen listing each resource with its outcome and errors (terminal only)")
+	cmd.PersistentFlags().BoolVar(&flagNoHistory, "no-history", false, "Do not record deleted resources in the history file")
+	cmd.PersistentFlags().IntVar(&flagJobs, "jobs", 4, "Number of resources to delete in parallel")
+	cmd.PersistentFlags().DurationVar(&flagTimeout, "timeout", 2*time.Minute, "Fail any single docker command or API request that takes longer (0 disables)")
+	cmd.PersistentFlags().IntVar(&flagReportInterval, "batch-delete-report-interval", 100, "Without a terminal, print progress every N deletions (0 disables)")
 
 	// Type-specific flags (only on root)
+	cmd.Flags().StringVar(&flagContainersOlderThan, "containers-older-than", "", "Like --older-than, for containers only")
+	cmd.Flags().StringVar(&flagImagesOlderThan, "images-older-than", "", "Like --older-than, for images only")
+	cmd.Flags().StringVar(&flagVolumesOlderThan, "volumes-older-than", "", "Like --older-than, for volumes only")
+	cmd.Flags().StringVar(&flagNetworksOlderThan, "networks-older-than", "", "Like --older-than, for networks only")
+	cmd.Flags().StringVar(&flagRepository, "repository", "", "Only images of this repository (exact or glob, e.g. registry.local/myapp); dangling images need --dangling")
 	cmd.Flags().StringVar(&flagMinSize, "min-size", "", "Only images larger than size (e.g., 100MB, 1GB)")
+	cmd.Flags().BoolVar(&flagDedupeLayers, "dedupe-layers", false, "Count image layers shared with kept images once in the space to recover (slow: inspects every image)")
+	cmd.Flags().StringVar(&flagMaxSize, "max-size", "", "Only images no larger than size (e.g., 50MB)")
 	cmd.Flags().BoolVar(&flagDangling, "dangling", false, "Only dangling images")
+	cmd.Flags().BoolVar(&flagUntagged, "untagged", false, "Only images without any tag (dangling or pulled by digest), suggested for deletion")
 	cmd.Flags().BoolVar(&flagNoDangling, "no-dangling", false, "Exclude dangling images")
+	cmd.Flags().StringArrayVar(&flagProtectTags, "protect-tag", nil, "Protect images whose tag matches a glob (repeatable; default latest, \"\" disables)")
+	cmd.Flags().IntVar(&flagKeepLast, "keep-last", 0, "Suggest tagged images beyond the newest N per repository")
+	cmd.Flags().BoolVar(&flagPruneUntaggedRemote, "prune-untagged-remote", false, "Suggest tagged images whose tag no longer exists in their registry")
+	cmd.Flags().BoolVar(&flagLayerAge, "prune-by-digest-age", false, "Date images by their newest history layer instead of Created for age filters (slow: reads each image's history)")
+	cmd.Flags().DurationVar(&flagWatch, "watch", 0, "Repeat the --gc or --yes sweep every interval until interrupted (e.g., 1h)")
 	cmd.Flags().BoolVar(&flagGC, "gc", false, "Non-interactive garbage collection mode (implies --yes and includes dangling images)")
 	cmd.Flags().BoolVar(&flagExited, "exited", false, "Only exited containers")
+	cmd.Flags().BoolVar(&flagRespectRestart, "respect-restart-policy", true, "Protect stopped containers whose restart policy is always or unless-stopped")
+	cmd.Flags().BoolVar(&flagCreated, "created", false, "Only containers that were created but never started")
+	cmd.Flags().BoolVar(&flagStop, "stop", false, "Stop running containers, then remove them (Compose and sweep.protect still win)")
+	cmd.Flags().DurationVar(&flagStopTimeout, "stop-timeout", 10*time.Second, "Time to wait for --stop before killing the container")
 	cmd.Flags().BoolVar(&flagAnonymous, "anonymous", false, "Only anonymous volumes")
+	cmd.Flags().BoolVar(&flagOrphans, "orphans", false, "Suggest volumes and networks of Compose projects with no containers left")
+	cmd.Flags().BoolVar(&flagVolumeSizes, "volume-sizes", false, "Measure local volume sizes (slow, needs access to volume mountpoints)")
+	cmd.Flags().BoolVar(&flagProtectIfChildRunning, "protect-if-child-running", true, "Protect images that are parents of in-use images")
+	cmd.Flags().BoolVar(&flagLeavesOnly, "leaves-only", false, "Only suggest leaf images: protect every image another local image was built on")
 
 	// Subcommands
 	cmd.AddCommand(NewContainersCmd())
 	cmd.AddCommand(NewImagesCmd())
 	cmd.AddCommand(NewVolumesCmd())
 	cmd.AddCommand(NewNetworksCmd())
+	cmd.AddCommand(NewPruneCmd())
+	cmd.AddCommand(NewApplyCmd())
+	cmd.AddCommand(NewHistoryCmd())
+	cmd.AddCommand(NewDfCmd())
+	cmd.AddCommand(NewDoctorCmd())
 	cmd.AddCommand(NewUpdateCmd())
+	cmd.AddCommand(NewVersionCmd())
+	cmd.AddCommand(NewInstallPluginCmd())
+	cmd.AddCommand(NewUninstallPluginCmd())
+
+	// Value completion; subcommands inherit --older-than
+	_ = cmd.RegisterFlagCompletionFunc("older-than", completeDurations)
+	for _, name := range []string{"containers-older-than", "images-older-than", "volumes-older-than", "networks-older-than"} {
+		_ = cmd.RegisterFlagCompletionFunc(name, completeDurations)
+	}
+	_ = cmd.RegisterFlagCompletionFunc("min-size", completeSizes)
+	_ = cmd.RegisterFlagCompletionFunc("sort", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return sweep.SortKeys, cobra.ShellCompDirectiveNoFileComp
+	})
+	_ = cmd.RegisterFlagCompletionFunc("theme", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return ui.ThemeNames, cobra.ShellCompDirectiveNoFileComp
+	})
 
 	return cmd
 }
 
-// buildConfig creates a Config from the current flags
-func buildConfig() (*config.Config, error) {
-	cfg := config.DefaultConfig()
+// completeDurations suggests common --older-than values
+func completeDurations(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return []string{"1h", "24h", "7d", "2w", "1m"}, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeSizes suggests common --min-size values
+func completeSizes(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return []string{"100MB", "500MB", "1GB", "5GB"}, cobra.ShellCompDirectiveNoFileComp
+}
+
+// buildConfig creates a Config from the config file and the current flags.
+// Precedence is: flags > config file > DefaultConfig().
+func buildConfig(cmd *cobra.Command) (*config.Config, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	flags := cmd.Flags()
 	cfg.Yes = flagYes
 	cfg.DryRun = flagDryRun
-	cfg.Dangling = flagDangling
-	cfg.NoDangling = flagNoDangling
-	cfg.Exited = flagExited
-	cfg.Anonymous = flagAnonymous
+	cfg.Force = flagForce
+
+	if flags.Changed("dangling") {
+		cfg.Dangling = flagDangling
+		if flagDangling {
+			cfg.NoDangling = false
+		}
+	}
+	if flags.Changed("no-dangling") {
+		cfg.NoDangling = flagNoDangling
+		if flagNoDangling {
+			cfg.Dangling = false
+		}
+	}
+	if flags.Changed("all") {
+		cfg.All = flagAll
+	}
+	if flags.Changed("untagged") {
+		cfg.Untagged = flagUntagged
+	}
+	if flags.Changed("keep-last") {
+		cfg.KeepLast = flagKeepLast
+	}
+	if flags.Changed("prune-untagged-remote") {
+		cfg.PruneUntaggedRemote = flagPruneUntaggedRemote
+	}
+	if flags.Changed("prune-by-digest-age") {
+		cfg.LayerAge = flagLayerAge
+	}
+	if flags.Changed("exited") {
+		cfg.Exited = flagExited
+		if flagExited {
+			cfg.CreatedOnly = false
+		}
+	}
+	if flags.Changed("created") {
+		cfg.CreatedOnly = flagCreated
+		if flagCreated {
+			cfg.Exited = false
+		}
+	}
+	if flags.Changed("stop") {
+		cfg.Stop = flagStop
+	}
+	if flags.Changed("stop-timeout") {
+		if flagStopTimeout < 0 {
+			return nil, fmt.Errorf("--stop-timeout must not be negative")
+		}
+		cfg.StopTimeout = flagStopTimeout
+	}
+	if flags.Changed("anonymous") {
+		cfg.Anonymous = flagAnonymous
+	}
+	if flags.Changed("orphans") {
+		cfg.Orphans = flagOrphans
+	}
+	if flags.Changed("volume-sizes") {
+		cfg.VolumeSizes = flagVolumeSizes
+	}
+	if flags.Changed("protect-if-child-running") {
+		cfg.ProtectParents = flagProtectIfChildRunning
+	}
+	if flags.Changed("leaves-only") {
+		cfg.LeavesOnly = flagLeavesOnly
+	}
+	if flags.Changed("respect-restart-policy") {
+		cfg.RespectRestartPolicy = flagRespectRestart
+	}
+	if flags.Changed("confirm") {
+		cfg.Confirm = flagConfirm
+	}
+	if flags.Changed("confirm-threshold") {
+		if flagConfirmThreshold < 0 {
+			return nil, fmt.Errorf("--confirm-threshold must not be negative")
+		}
+		cfg.ConfirmThreshold = flagConfirmThreshold
+	}
 
 	if flagGC {
-		cfg.Yes = true
+		cfg.Yes = !flagInteractive
 		cfg.Dangling = false
 		cfg.NoDangling = false
-	} else if !flagDangling && !flagNoDangling {
+	} else if !cfg.Dangling && !cfg.NoDangling && !cfg.Untagged {
 		// Default policy for root sweeps: hide dangling images unless requested.
 		cfg.NoDangling = true
 	}
 
-	if flagOlderThan != "" {
+	if flags.Changed("older-than") {
 		d, err := config.ParseDuration(flagOlderThan)
 		if err != nil {
 			return nil, err
@@ -103,7 +378,123 @@ func buildConfig() (*config.Config, error) {
 		cfg.OlderThan = d
 	}
 
-	if flagMinSize != "" {
+	if flags.Changed("min-age") {
+		d, err := config.ParseDuration(flagMinAge)
+		if err != nil {
+			return nil, err
+		}
+		cfg.MinAge = d
+	}
+
+	if flags.Changed("grace") {
+		d, err := config.ParseDuration(flagGrace)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Grace = d
+	}
+
+	if flags.Changed("newer-than") {
+		d, err := config.ParseDuration(flagNewerThan)
+		if err != nil {
+			return nil, err
+		}
+		cfg.NewerThan = d
+	}
+
+	if flags.Changed("until") {
+		t, err := config.ParseUntil(flagUntil, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		cfg.Until = t
+	}
+
+	if cfg.OlderThan > 0 && cfg.NewerThan > 0 && cfg.NewerThan <= cfg.OlderThan {
+		return nil, fmt.Errorf("--newer-than must be larger than --older-than to form a time window")
+	}
+
+	typeOlderThan := []struct {
+		flag, value, resourceType string
+	}{
+		{"containers-older-than", flagContainersOlderThan, string(sweep.TypeContainer)},
+		{"images-older-than", flagImagesOlderThan, string(sweep.TypeImage)},
+		{"volumes-older-than", flagVolumesOlderThan, string(sweep.TypeVolume)},
+		{"networks-older-than", flagNetworksOlderThan, string(sweep.TypeNetwork)},
+	}
+	for _, t := range typeOlderThan {
+		if !flags.Changed(t.flag) {
+			continue
+		}
+		d, err := config.ParseDuration(t.value)
+		if err != nil {
+			return nil, fmt.Errorf("--%s: %w", t.flag, err)
+		}
+		if cfg.NewerThan > 0 && cfg.NewerThan <= d {
+			return nil, fmt.Errorf("--newer-than must be larger than --%s to form a time window", t.flag)
+		}
+		if cfg.TypeOlderThan == nil {
+			cfg.TypeOlderThan = make(map[string]time.Duration)
+		}
+		cfg.TypeOlderThan[t.resourceType] = d
+	}
+
+	if flags.Changed("name") {
+		re, err := config.ParseNamePattern(flagName)
+		if err != nil {
+			return nil, err
+		}
+		cfg.NamePattern = re
+	}
+
+	if flags.Changed("repository") {
+		pattern, err := config.ParseRepositoryPattern(flagRepository)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Repository = pattern
+	}
+
+	if flags.Changed("label") {
+		selectors, err := config.ParseLabelSelectors(flagLabels)
+		if err != nil {
+			return nil, err
+		}
+		cfg.LabelSelectors = selectors
+	}
+
+	if flags.Changed("exclude") {
+		patterns, err := config.ParseExcludePatterns(flagExclude)
+		if err != nil {
+			return nil, err
+		}
+		cfg.ExcludePatterns = patterns
+	}
+
+	if flags.Changed("protect-label") {
+		keys, err := config.ParseProtectLabels(flagProtect)
+		if err != nil {
+			return nil, err
+		}
+		cfg.ProtectLabels = keys
+	}
+
+	if flags.Changed("protect-tag") {
+		patterns, err := config.ParseProtectTags(flagProtectTags)
+		if err != nil {
+			return nil, err
+		}
+		cfg.ProtectTags = patterns
+	}
+
+	if flags.Changed("protect-any-value") {
+		cfg.ProtectAnyValue = flagProtectAny
+	}
+	if flags.Changed("protect-active-projects") {
+		cfg.ProtectActiveProjects = flagProtectActive
+	}
+
+	if flags.Changed("min-size") {
 		s, err := config.ParseSize(flagMinSize)
 		if err != nil {
 			return nil, err
@@ -111,15 +502,212 @@ func buildConfig() (*config.Config, error) {
 		cfg.MinSize = s
 	}
 
+	if flags.Changed("max-size") {
+		s, err := config.ParseSize(flagMaxSize)
+		if err != nil {
+			return nil, err
+		}
+		cfg.MaxSize = s
+	}
+
+	if cfg.MinSize > 0 && cfg.MaxSize > 0 && cfg.MinSize > cfg.MaxSize {
+		return nil, fmt.Errorf("--min-size must not be larger than --max-size")
+	}
+
+	if flags.Changed("sort") {
+		if err := sweep.ValidateSortKey(flagSort); err != nil {
+			return nil, err
+		}
+		cfg.Sort = flagSort
+	}
+
+	if flags.Changed("max-suggested") {
+		if flagMaxSuggested < 0 {
+			return nil, fmt.Errorf("--max-suggested must be zero or positive")
+		}
+		cfg.MaxSuggested = flagMaxSuggested
+	}
+
+	if flags.Changed("fast") {
+		cfg.Fast = flagFast
+	}
+	if cfg.Fast && cfg.LeavesOnly {
+		return nil, fmt.Errorf("--leaves-only needs image inspect output, which --fast skips")
+	}
+	if cfg.Fast {
+		warnFastFlags(cmd, cfg)
+	}
+
 	return cfg, nil
 }
 
-func Execute(version string) {
+// warnFastFlags names the flags that need inspect data --fast does not fetch,
+// and the protections that still make it inspect some resources
+func warnFastFlags(cmd *cobra.Command, cfg *config.Config) {
+	flags := cmd.Flags()
+	var names []string
+	for _, name := range []string{
+		"older-than", "containers-older-than", "images-older-than", "volumes-older-than", "networks-older-than",
+		"newer-than", "until", "min-age", "grace", "protect-active-projects", "prune-by-digest-age",
+	} {
+		if flags.Changed(name) {
+			names = append(names, "--"+name)
+		}
+	}
+	if len(names) > 0 {
+		fmt.Fprintf(os.Stderr, "warning: --fast skips inspect; %s may miss resources whose labels or creation time only inspect reports\n", strings.Join(names, ", "))
+	}
+
+	var inspected, off []string
+	if cfg.RespectRestartPolicy {
+		inspected = append(inspected, "stopped containers (restart-policy protection)")
+		off = append(off, "--respect-restart-policy=false")
+	}
+	if cfg.ProtectParents {
+		inspected = append(inspected, "in-use images and their parents (parent-image protection)")
+		off = append(off, "--protect-if-child-running=false")
+	}
+	if len(inspected) > 0 {
+		fmt.Fprintf(os.Stderr, "warning: --fast still inspects %s; pass %s to skip them\n", strings.Join(inspected, " and "), strings.Join(off, " or "))
+	}
+}
+
+// applyHostFlags points every command at the daemon given with --host or
+// --socket. Both pick one daemon, so they exclude each other and --context.
+func applyHostFlags() error {
+	host := flagHost
+	if flagSocket != "" {
+		if flagHost != "" {
+			return fmt.Errorf("--socket and --host are mutually exclusive")
+		}
+		path, err := filepath.Abs(flagSocket)
+		if err != nil {
+			return fmt.Errorf("invalid --socket: %w", err)
+		}
+		host = "unix://" + path
+	}
+	if host != "" && flagContext != "" {
+		return fmt.Errorf("--context cannot be combined with --host or --socket")
+	}
+	return docker.SetHost(host)
+}
+
+// applyInteractiveFlag makes --interactive win over --yes. Like the picker
+// itself, it needs a terminal.
+func applyInteractiveFlag() error {
+	if !flagInteractive {
+		return nil
+	}
+
+	var err error
+	switch {
+	case machineOutput():
+		err = fmt.Errorf("--interactive cannot be used with %s", outputFlagName())
+	case !ui.IsTTY():
+		err = fmt.Errorf("--interactive requires a terminal")
+	}
+	if err != nil {
+		printError(err)
+		return err
+	}
+
+	flagYes = false
+	return nil
+}
+
+// checkYesScope refuses a bare --yes, which deletes everything suggested,
+// unless a filter narrows it or --all-suggested confirms it. --gc and
+// --dry-run are exempt.
+func checkYesScope(cfg *config.Config) error {
+	if !cfg.Yes || flagGC || cfg.DryRun || flagAllSuggested {
+		return nil
+	}
+	if cfg.OlderThan > 0 || len(cfg.TypeOlderThan) > 0 || cfg.MinSize > 0 || cfg.NamePattern != nil || cfg.Repository != "" || len(cfg.LabelSelectors) > 0 || !cfg.Until.IsZero() {
+		return nil
+	}
+	return fmt.Errorf("--yes without --older-than, --until, --min-size, --name, --repository or --label deletes everything suggested; add --all-suggested to confirm")
+}
+
+// confirmYes asks for a last y/N before a --yes deletion when --prompt is
+// set. Without a terminal on stdin it does not ask, so scripts never block.
+func confirmYes(toDelete []sweep.Resource) bool {
+	if !flagPrompt || !ui.IsInputTTY() {
+		return true
+	}
+
+	var size int64
+	for _, r := range toDelete {
+		size += r.Size()
+	}
+	fmt.Printf("  Delete %d resources (%s)? [y/N] ", len(toDelete), ui.FormatSize(size))
+	reader := bufio.NewReader(os.Stdin)
+	response, _ := reader.ReadString('\n')
+	response = strings.TrimSpace(strings.ToLower(response))
+	if response != "y" && response != "yes" {
+		fmt.Printf("\n  %s Deletion cancelled\n\n", ui.MutedStyle.Render("●"))
+		return false
+	}
+	fmt.Println()
+	return true
+}
+
+// deleteProgress returns a callback that updates the deletion spinner, or
+// prints periodic progress lines when deleting without a terminal
+func deleteProgress(spinner func(done, total int)) sweep.ProgressFunc {
+	if ui.IsTTY() {
+		return sweep.ProgressFunc(spinner)
+	}
+	if flagReportInterval <= 0 || flagQuiet {
+		return nil
+	}
+	return func(done, total int) {
+		if done%flagReportInterval == 0 && done < total {
+			fmt.Print(ui.RenderProgress(done, total))
+		}
+	}
+}
+
+// pickerOptions returns the picker options derived from cfg
+func pickerOptions(cfg *config.Config) ui.PickerOptions {
+	opts := ui.PickerOptions{
+		Confirm:          cfg.Confirm,
+		ConfirmThreshold: cfg.ConfirmThreshold,
+		ShowProtected:    flagShowProtected,
+		GroupBy:          strings.TrimSpace(flagGroupBy),
+		OnExit:           rememberSelection,
+	}
+	if flagResume {
+		opts.Selection = loadSelection()
+	}
+	return opts
+}
+
+// Exit codes
+const (
+	exitOK      = 0   // success, including nothing to clean and --dry-run
+	exitFatal   = 1   // the run could not proceed (bad flags, daemon unavailable)
+	exitPartial = 2   // some resources failed to delete
+	exitSignal  = 130 // an interrupt stopped the deletion early
+)
+
+// failedDeletions counts the resources that failed to delete during this run
+var failedDeletions int
+
+func Execute(version, commit, date string) {
 	update.CurrentVersion = version
+	buildCommit = commit
+	buildDate = date
 
 	if err := NewRootCmd(version).Execute(); err != nil {
-		os.Exit(1)
+		os.Exit(exitFatal)
+	}
+	if interrupted {
+		os.Exit(exitSignal)
 	}
+	if failedDeletions > 0 {
+		os.Exit(exitPartial)
+	}
+	os.Exit(exitOK)
 }
 
 func runRoot(cmd *cobra.Command, args []string) error {
@@ -135,75 +723,73 @@ func runRoot(cmd *cobra.Command, args []string) error {
 	analyzeNetworks := flagNetworks || !selectedTypes
 
 	if err := validateTypeSpecificFlags(analyzeContainers, analyzeImages, analyzeVolumes, analyzeNetworks); err != nil {
-		fmt.Print(ui.RenderError(err.Error()))
+		printError(err)
 		return err
 	}
 
 	// Build config from flags
-	cfg, err := buildConfig()
+	cfg, err := buildConfig(cmd)
 	if err != nil {
-		fmt.Print(ui.RenderError(err.Error()))
+		printError(err)
+		return err
+	}
+
+	if err := checkYesScope(cfg); err != nil {
+		printError(err)
 		return err
 	}
 
 	// Check Docker is available
 	if err := docker.CheckAvailable(); err != nil {
-		fmt.Print(ui.RenderError(err.Error()))
+		printError(err)
 		return err
 	}
 
-	fmt.Print(ui.RenderHeader())
-
-	if flagYes || flagGC {
-		result, err := analyzeRootResources(cfg, analyzeContainers, analyzeImages, analyzeVolumes, analyzeNetworks)
+	if machineOutput() {
+		result, err := analyzeRootResources(context.Background(), cfg, analyzeContainers, analyzeImages, analyzeVolumes, analyzeNetworks)
 		if err != nil {
 			if err.Error() == "cancelled" {
 				return nil
 			}
-			fmt.Print(ui.RenderError(err.Error()))
+			printError(err)
 			return err
 		}
-
-		if result.IsEmpty() {
-			fmt.Print(ui.RenderNoResources())
-			return nil
+		if csvOutput() {
+			return writeCSV(result.Resources())
 		}
-
-		toDelete := result.Suggested()
-		if len(toDelete) == 0 {
-			fmt.Print(ui.RenderNoResources())
-			return nil
+		if formatOutput() {
+			return writeFormatResult(result, cfg.Yes)
 		}
+		return writeJSONResult(result, cfg.Yes)
+	}
 
-		if flagDryRun {
-			fmt.Print(ui.RenderDryRun(toDelete))
-			return nil
-		}
+	fmt.Print(ui.RenderHeader())
 
-		var deleted int
-		var errors []error
-		if err := ui.RunWithSpinner("Deleting selected resources...", func() error {
-			deleted, errors = sweep.DeleteResources(toDelete)
-			return nil
-		}); err != nil {
-			if err.Error() == "cancelled" {
-				return nil
+	sweepOnce := func(ctx context.Context) error {
+		if flagWatch > 0 && cmd.Flags().Changed("until") {
+			// A relative --until slides with each run, like --older-than
+			if t, err := config.ParseUntil(flagUntil, time.Now()); err == nil {
+				cfg.Until = t
 			}
-			fmt.Print(ui.RenderError(err.Error()))
-			return err
 		}
+		return sweepSuggested(ctx, cfg, analyzeContainers, analyzeImages, analyzeVolumes, analyzeNetworks)
+	}
 
-		for _, err := range errors {
-			fmt.Printf("  %s\n", ui.RenderErrorInline(err.Error()))
-		}
+	if flagWatch > 0 {
+		return runWatch(flagWatch, sweepOnce)
+	}
 
-		fmt.Print(ui.RenderSummary(deleted, len(toDelete)))
+	if (flagYes || flagGC) && !flagInteractive {
+		if err := sweepOnce(context.Background()); err != nil && err.Error() != "cancelled" {
+			printError(err)
+			return err
+		}
 		return nil
 	}
 
 	if !ui.IsTTY() {
 		err := fmt.Errorf("interactive mode requires a terminal; use --yes to delete suggested resources")
-		fmt.Print(ui.RenderError(err.Error()))
+		printError(err)
 		return err
 	}
 
@@ -211,12 +797,12 @@ func runRoot(cmd *cobra.Command, args []string) error {
 	enableDanglingToggle := analyzeImages && !flagDangling
 
 	for {
-		result, err := analyzeRootResources(cfg, analyzeContainers, analyzeImages, analyzeVolumes, analyzeNetworks)
+		result, err := analyzeRootResources(context.Background(), cfg, analyzeContainers, analyzeImages, analyzeVolumes, analyzeNetworks)
 		if err != nil {
 			if err.Error() == "cancelled" {
 				return nil
 			}
-			fmt.Print(ui.RenderError(err.Error()))
+			printError(err)
 			return err
 		}
 
@@ -225,12 +811,12 @@ func runRoot(cmd *cobra.Command, args []string) error {
 			return nil
 		}
 
-		toDelete, action, err := ui.RunPickerWithOptions(result, ui.PickerOptions{
-			EnableDanglingToggle: enableDanglingToggle,
-			ShowDangling:         showDangling,
-		})
+		opts := pickerOptions(cfg)
+		opts.EnableDanglingToggle = enableDanglingToggle
+		opts.ShowDangling = showDangling
+		toDelete, action, err := ui.RunPickerWithOptions(result, opts)
 		if err != nil {
-			fmt.Print(ui.RenderError(err.Error()))
+			printError(err)
 			return err
 		}
 
@@ -248,83 +834,125 @@ func runRoot(cmd *cobra.Command, args []string) error {
 		}
 
 		if flagDryRun {
-			fmt.Print(ui.RenderDryRun(toDelete))
+			fmt.Print(ui.RenderDryRun(toDelete, result))
 			return nil
 		}
 
-		var deleted int
-		var errors []error
-		if err := ui.RunWithSpinner("Deleting selected resources...", func() error {
-			deleted, errors = sweep.DeleteResources(toDelete)
-			return nil
-		}); err != nil {
-			if err.Error() == "cancelled" {
-				return nil
-			}
-			fmt.Print(ui.RenderError(err.Error()))
+		if err := deleteWithProgress("Deleting selected resources...", toDelete); err != nil {
+			printError(err)
 			return err
 		}
-
-		for _, err := range errors {
-			fmt.Printf("  %s\n", ui.RenderErrorInline(err.Error()))
+		if interrupted {
+			return nil
 		}
-
-		fmt.Print(ui.RenderSummary(deleted, len(toDelete)))
 	}
 }
 
-func analyzeRootResources(cfg *config.Config, includeContainers, includeImages, includeVolumes, includeNetworks bool) (*sweep.Result, error) {
-	ms := ui.NewMultiSpinner()
-	result := &sweep.Result{}
+// sweepSuggested deletes the suggested resources without the picker, as --yes
+// and --gc do. Cancelling a spinner, ctx or the analysis with a signal returns
+// a "cancelled" error.
+func sweepSuggested(ctx context.Context, cfg *config.Config, includeContainers, includeImages, includeVolumes, includeNetworks bool) error {
+	result, err := analyzeRootResources(ctx, cfg, includeContainers, includeImages, includeVolumes, includeNetworks)
+	if err != nil {
+		return err
+	}
 
-	if includeContainers {
-		ms.Add("Analyzing containers...", func() error {
-			containers, err := sweep.AnalyzeContainersWithConfig(cfg)
-			if err != nil {
-				return err
-			}
-			result.Containers = containers
-			return nil
-		})
+	toDelete := result.Suggested()
+	if result.IsEmpty() || len(toDelete) == 0 {
+		fmt.Print(ui.RenderNoResources())
+		return nil
 	}
 
-	if includeImages {
-		ms.Add("Analyzing images...", func() error {
-			images, err := sweep.AnalyzeImagesWithConfig(cfg)
-			if err != nil {
-				return err
-			}
-			result.Images = images
-			return nil
-		})
+	if flagDryRun {
+		fmt.Print(ui.RenderDryRun(toDelete, result))
+		return nil
 	}
 
-	if includeVolumes {
-		ms.Add("Analyzing volumes...", func() error {
-			volumes, err := sweep.AnalyzeVolumesWithConfig(cfg)
-			if err != nil {
-				return err
-			}
-			result.Volumes = volumes
-			return nil
-		})
+	if !confirmYes(toDelete) {
+		return nil
 	}
 
-	if includeNetworks {
-		ms.Add("Analyzing networks...", func() error {
-			networks, err := sweep.AnalyzeNetworksWithConfig(cfg)
-			if err != nil {
+	return deleteWithProgress("Deleting selected resources...", toDelete)
+}
+
+func analyzeRootResources(ctx context.Context, cfg *config.Config, includeContainers, includeImages, includeVolumes, includeNetworks bool) (*sweep.Result, error) {
+	result := &sweep.Result{Filtered: sweep.NewFiltered(), Sort: cfg.Sort}
+	if err := analyze(ctx, func(ctx context.Context) error {
+		ms := ui.NewMultiSpinner()
+		// The analyses run concurrently and share one inspect of all containers
+		cache := docker.NewContainerCache()
+
+		if includeContainers {
+			ms.Add("Analyzing containers...", func() error {
+				containers, err := sweep.AnalyzeContainersContext(ctx, cfg, result.Filtered, cache)
+				if err != nil {
+					return err
+				}
+				result.Containers = containers
+				return nil
+			})
+		}
+
+		if includeImages {
+			ms.Add("Analyzing images...", func() error {
+				images, err := sweep.AnalyzeImagesContext(ctx, cfg, result.Filtered, cache)
+				if err != nil {
+					return err
+				}
+				result.Images = images
+				return nil
+			})
+		}
+
+		if includeVolumes {
+			ms.Add("Analyzing volumes...", func() error {
+				volumes, err := sweep.AnalyzeVolumesContext(ctx, cfg, result.Filtered, cache)
+				if err != nil {
+					return err
+				}
+				result.Volumes = volumes
+				return nil
+			})
+		}
+
+		if includeNetworks {
+			ms.Add("Analyzing networks...", func() error {
+				networks, err := sweep.AnalyzeNetworksContext(ctx, cfg, result.Filtered, cache)
+				if err != nil {
+					return err
+				}
+				result.Networks = networks
+				return nil
+			})
+		}
+
+		if err := ms.RunConcurrent(); err != nil {
+			return err
+		}
+
+		if cfg.Orphans {
+			if err := sweep.MarkOrphansContext(ctx, result); err != nil {
 				return err
 			}
-			result.Networks = networks
-			return nil
-		})
-	}
+		}
+		result.LimitSuggested(cfg.MaxSuggested)
 
-	if err := ms.Run(); err != nil {
+		if flagDedupeLayers && includeImages {
+			// Without the index, sizes fall back to the per-image sum
+			_ = ui.RunWithSpinner("Indexing image layers...", func() error {
+				return result.IndexLayersContext(ctx)
+			})
+		}
+		return nil
+	}); err != nil {
 		return nil, err
 	}
 
+	if flagShowFiltered && !machineOutput() {
+		fmt.Print(ui.RenderFiltered(result.Filtered))
+	}
+	printProtected(result)
+
 	return result, nil
 }
 
@@ -333,10 +961,46 @@ func validateTypeSpecificFlags(includeContainers, includeImages, includeVolumes,
 		return fmt.Errorf("--exited only applies to containers; include --containers or -c")
 	}
 
+	if flagContainersOlderThan != "" && !includeContainers {
+		return fmt.Errorf("--containers-older-than only applies to containers; include --containers or -c")
+	}
+
+	if flagImagesOlderThan != "" && !includeImages {
+		return fmt.Errorf("--images-older-than only applies to images; include --images or -i")
+	}
+
+	if flagVolumesOlderThan != "" && !includeVolumes {
+		return fmt.Errorf("--volumes-older-than only applies to volumes; include --volumes or -v")
+	}
+
+	if flagNetworksOlderThan != "" && !includeNetworks {
+		return fmt.Errorf("--networks-older-than only applies to networks; include --networks or -n")
+	}
+
+	if flagCreated && !includeContainers {
+		return fmt.Errorf("--created only applies to containers; include --containers or -c")
+	}
+
+	if flagExited && flagCreated {
+		return fmt.Errorf("--exited and --created are mutually exclusive")
+	}
+
+	if flagStop && !includeContainers {
+		return fmt.Errorf("--stop only applies to containers; include --containers or -c")
+	}
+
 	if flagMinSize != "" && !includeImages {
 		return fmt.Errorf("--min-size only applies to images; include --images or -i")
 	}
 
+	if flagMaxSize != "" && !includeImages {
+		return fmt.Errorf("--max-size only applies to images; include --images or -i")
+	}
+
+	if flagRepository != "" && !includeImages {
+		return fmt.Errorf("--repository only applies to images; include --images or -i")
+	}
+
 	if flagDangling && !includeImages {
 		return fmt.Errorf("--dangling only applies to images; include --images or -i")
 	}
@@ -345,10 +1009,70 @@ func validateTypeSpecificFlags(includeContainers, includeImages, includeVolumes,
 		return fmt.Errorf("--no-dangling only applies to images; include --images or -i")
 	}
 
+	if flagUntagged && !includeImages {
+		return fmt.Errorf("--untagged only applies to images; include --images or -i")
+	}
+
+	if flagUntagged && flagNoDangling {
+		return fmt.Errorf("--untagged and --no-dangling are mutually exclusive")
+	}
+
+	if flagKeepLast != 0 && !includeImages {
+		return fmt.Errorf("--keep-last only applies to images; include --images or -i")
+	}
+
+	if len(flagProtectTags) > 0 && !includeImages {
+		return fmt.Errorf("--protect-tag only applies to images; include --images or -i")
+	}
+
+	if flagDedupeLayers && !includeImages {
+		return fmt.Errorf("--dedupe-layers only applies to images; include --images or -i")
+	}
+
+	if flagPruneUntaggedRemote && !includeImages {
+		return fmt.Errorf("--prune-untagged-remote only applies to images; include --images or -i")
+	}
+
+	if flagLayerAge && !includeImages {
+		return fmt.Errorf("--prune-by-digest-age only applies to images; include --images or -i")
+	}
+
+	if flagLeavesOnly && !includeImages {
+		return fmt.Errorf("--leaves-only only applies to images; include --images or -i")
+	}
+
+	if flagKeepLast < 0 {
+		return fmt.Errorf("--keep-last must not be negative")
+	}
+
 	if flagDangling && flagNoDangling {
 		return fmt.Errorf("--dangling and --no-dangling are mutually exclusive")
 	}
 
+	if flagWatch < 0 {
+		return fmt.Errorf("--watch must not be negative")
+	}
+
+	if flagWatch > 0 && flagInteractive {
+		return fmt.Errorf("--watch and --interactive are mutually exclusive")
+	}
+
+	if flagWatch > 0 && !flagYes && !flagGC {
+		return fmt.Errorf("--watch needs --gc or --yes; the picker never opens in watch mode")
+	}
+
+	if flagWatch > 0 && flagPrompt {
+		return fmt.Errorf("--watch and --prompt are mutually exclusive")
+	}
+
+	if flagWatch > 0 && flagResults {
+		return fmt.Errorf("--watch and --results are mutually exclusive")
+	}
+
+	if flagWatch > 0 && machineOutput() {
+		return fmt.Errorf("--watch cannot be used with %s", outputFlagName())
+	}
+
 	if flagGC && flagDangling {
 		return fmt.Errorf("--gc and --dangling are mutually exclusive")
 	}
@@ -361,5 +1085,13 @@ func validateTypeSpecificFlags(includeContainers, includeImages, includeVolumes,
 		return fmt.Errorf("--anonymous only applies to volumes; include --volumes or -v")
 	}
 
+	if flagOrphans && !includeVolumes && !includeNetworks {
+		return fmt.Errorf("--orphans only applies to volumes and networks; include --volumes or --networks")
+	}
+
+	if flagVolumeSizes && !includeVolumes {
+		return fmt.Errorf("--volume-sizes only applies to volumes; include --volumes or -v")
+	}
+
 	return nil
 }
@@ -3,7 +3,9 @@ package cmd
 import (
 	"bufio"
 	"context"
+	"errors"
 	"fmt"
+	"net"
 	"os"
 	"strings"
 
@@ -16,6 +18,7 @@ import (
 var (
 	flagCheckUpdate bool
 	flagYesUpdate   bool
+	flagRollback    bool
 )
 
 func NewUpdateCmd() *cobra.Command {
@@ -27,17 +30,23 @@ func NewUpdateCmd() *cobra.Command {
 Examples:
   docker sweep update         # Check and prompt to update
   docker sweep update --check # Only check, don't install
-  docker sweep update --yes   # Update without confirmation`,
+  docker sweep update --yes   # Update without confirmation
+  docker sweep update --rollback # Restore the version replaced by the last update`,
 		RunE: runUpdate,
 	}
 
 	cmd.Flags().BoolVar(&flagCheckUpdate, "check", false, "Only check for updates, don't install")
 	cmd.Flags().BoolVar(&flagYesUpdate, "yes", false, "Update without confirmation")
+	cmd.Flags().BoolVar(&flagRollback, "rollback", false, "Restore the version replaced by the last update")
 
 	return cmd
 }
 
 func runUpdate(cmd *cobra.Command, args []string) error {
+	if flagRollback {
+		return runRollback()
+	}
+
 	ctx := context.Background()
 
 	fmt.Printf("\n  %s Current version: %s\n", ui.CheckStyle.Render(), ui.BoldStyle.Render(update.CurrentVersion))
@@ -49,7 +58,7 @@ func runUpdate(cmd *cobra.Command, args []string) error {
 		release, hasUpdate, err = update.CheckForUpdate(ctx)
 		return err
 	}); err != nil {
-		fmt.Print(ui.RenderError(err.Error()))
+		fmt.Print(ui.RenderError(updateErrorMessage(err)))
 		return err
 	}
 
@@ -83,17 +92,53 @@ func runUpdate(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	checksumsURL, err := release.GetChecksumAsset()
+	if err != nil {
+		fmt.Print(ui.RenderError(err.Error()))
+		return err
+	}
+
 	if err := ui.RunWithSpinner(fmt.Sprintf("Downloading %s...", release.TagName), func() error {
-		return update.DownloadAndInstall(ctx, downloadURL)
+		return update.DownloadAndInstall(ctx, downloadURL, checksumsURL)
 	}); err != nil {
+		fmt.Print(ui.RenderError(updateErrorMessage(err)))
+		return err
+	}
+
+	fmt.Printf("\n  %s Updated to %s\n\n", ui.CheckStyle.Render(), ui.SuccessStyle.Render(release.TagName))
+	return nil
+}
+
+// updateErrorMessage adds a hint for the common ways an update fails
+func updateErrorMessage(err error) string {
+	msg := err.Error()
+	var netErr net.Error
+	switch {
+	case strings.Contains(strings.ToLower(msg), "permission denied"):
+		msg += "\n  Hint: if installed as Docker plugin, ensure write access to ~/.docker/cli-plugins/docker-sweep"
+	case errors.As(err, &netErr) && netErr.Timeout():
+		msg += fmt.Sprintf("\n  Hint: check HTTPS_PROXY/NO_PROXY, or raise %s (e.g. 5m)", update.TimeoutEnv)
+	}
+	return msg
+}
+
+func runRollback() error {
+	if flagCheckUpdate {
+		err := fmt.Errorf("--rollback and --check are mutually exclusive")
+		fmt.Print(ui.RenderError(err.Error()))
+		return err
+	}
+
+	if err := update.Rollback(); err != nil {
 		msg := err.Error()
-		if strings.Contains(strings.ToLower(msg), "permission denied") {
-			msg += "\n  Hint: if installed as Docker plugin, ensure write access to ~/.docker/cli-plugins/docker-sweep"
+		if errors.Is(err, update.ErrNoBackup) {
+			msg += "\n  A backup is kept only after docker sweep update installs a new version"
 		}
 		fmt.Print(ui.RenderError(msg))
 		return err
 	}
 
-	fmt.Printf("\n  %s Updated to %s\n\n", ui.CheckStyle.Render(), ui.SuccessStyle.Render(release.TagName))
+	fmt.Printf("\n  %s Restored the previous version (was %s); run it again to undo\n\n",
+		ui.CheckStyle.Render(), ui.BoldStyle.Render(update.CurrentVersion))
 	return nil
 }
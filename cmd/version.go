@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+
+	"github.com/spf13/cobra"
+
+	"github.com/midnattsol/docker-sweep/internal/docker"
+)
+
+// Build metadata injected through ldflags in main, empty for plain go builds
+var (
+	buildCommit string
+	buildDate   string
+)
+
+// versionInfo is printed by the version command
+type versionInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildDate string `json:"buildDate"`
+	GoVersion string `json:"goVersion"`
+	Platform  string `json:"platform"`
+	Runtime   string `json:"runtime"`
+}
+
+func NewVersionCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "version",
+		Short: "Show version and build information",
+		Long: `Show the version, commit, build date, Go version and detected container runtime.
+
+Examples:
+  docker sweep version
+  docker sweep version --output json`,
+		Args: cobra.NoArgs,
+		RunE: runVersion,
+	}
+}
+
+func runVersion(cmd *cobra.Command, args []string) error {
+	info := versionInfo{
+		Version:   cmd.Root().Version,
+		Commit:    buildCommit,
+		BuildDate: buildDate,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+		Runtime:   docker.Runtime(),
+	}
+
+	// go build records the VCS revision even without ldflags
+	if info.Commit == "" {
+		if bi, ok := debug.ReadBuildInfo(); ok {
+			for _, s := range bi.Settings {
+				if s.Key == "vcs.revision" {
+					info.Commit = s.Value
+				}
+			}
+		}
+	}
+
+	if jsonOutput() {
+		return writeJSON(info)
+	}
+
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "Version:    %s\n", info.Version)
+	fmt.Fprintf(out, "Commit:     %s\n", valueOrUnknown(info.Commit))
+	fmt.Fprintf(out, "Built:      %s\n", valueOrUnknown(info.BuildDate))
+	fmt.Fprintf(out, "Go version: %s\n", info.GoVersion)
+	fmt.Fprintf(out, "Platform:   %s\n", info.Platform)
+	fmt.Fprintf(out, "Runtime:    %s\n", info.Runtime)
+	return nil
+}
+
+func valueOrUnknown(s string) string {
+	if s == "" {
+		return "unknown"
+	}
+	return s
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/midnattsol/docker-sweep/internal/ui"
+)
+
+// runWatch runs sweep every interval until SIGINT or SIGTERM. Runs never
+// overlap: one that outlasts the interval skips the ticks it missed. A signal
+// cancels the ctx sweep gets, which stops an analysis or a deletion early.
+func runWatch(interval time.Duration, sweep func(ctx context.Context) error) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	for {
+		start := time.Now()
+		fmt.Print(ui.RenderWatch("Sweep at " + start.Format("2006-01-02 15:04:05")))
+
+		if err := sweep(ctx); err != nil {
+			if err.Error() == "cancelled" {
+				return nil
+			}
+			// Keep watching: the daemon may be restarting or briefly unavailable
+			printError(err)
+		}
+		if interrupted {
+			fmt.Print(ui.RenderWatch("Watch stopped"))
+			return nil
+		}
+
+		wait := interval - time.Since(start)%interval
+		next := time.Now().Add(wait)
+		fmt.Print(ui.RenderWatch("Next sweep at " + next.Format("2006-01-02 15:04:05")))
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			fmt.Print(ui.RenderWatch("Watch stopped"))
+			return nil
+		case <-timer.C:
+		}
+	}
+}
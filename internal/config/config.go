@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"path"
 	"regexp"
 	"strconv"
 	"strings"
@@ -16,18 +17,275 @@ type Config struct {
 
 	// Filters
 	OlderThan time.Duration // Only resources older than this
+	NewerThan time.Duration // Only resources newer than this
+	Until     time.Time     // Only resources created before this instant
+	MinAge    time.Duration // Protect resources younger than this
+	Grace     time.Duration // Protect Compose volumes and networks younger than this
 	MinSize   int64         // Only images larger than this (bytes)
+	MaxSize   int64         // Only images no larger than this (bytes)
+
+	TypeOlderThan map[string]time.Duration // Per-type OlderThan overrides, keyed by resource type
+
+	NamePattern    *regexp.Regexp  // Only resources whose name matches (nil matches all)
+	LabelSelectors []LabelSelector // Only resources carrying all of these labels
+	Repository     string          // Only images whose repository matches this glob
+
+	ExcludePatterns []string // Protect resources whose name matches any glob
+	ProtectTags     []string // Protect images whose tag matches any glob
 
 	// Type-specific filters
-	Dangling   bool // Only dangling images
-	NoDangling bool // Exclude dangling images
-	Exited     bool // Only exited containers
-	Anonymous  bool // Only anonymous volumes
+	Dangling    bool // Only dangling images
+	NoDangling  bool // Exclude dangling images
+	Untagged    bool // Only images without any tag, dangling or referenced by digest
+	KeepLast    int  // Suggest tagged images beyond the newest N per repository
+	Exited      bool // Only exited containers
+	CreatedOnly bool // Only containers that were created but never started
+	Anonymous   bool // Only anonymous volumes
+
+	VolumeSizes bool // Measure volume sizes from their mountpoints
+	Orphans     bool // Suggest volumes and networks of Compose projects without containers
+
+	PruneUntaggedRemote bool // Suggest tagged images whose tag is gone from their registry
+	LayerAge            bool // Date images by their newest history entry instead of Created
+	Fast                bool // Skip per-resource inspect calls and rely on list output only
+	All                 bool // Suggest unused resources too (named volumes, tagged images)
+
+	// Safety
+	ProtectLabels         []string // Label keys that protect a resource when set to "true"
+	ProtectAnyValue       bool     // A protect label protects whatever its value
+	ProtectParents        bool     // Protect images that are parents of in-use images
+	LeavesOnly            bool     // Protect images that other local images were built on
+	RespectRestartPolicy  bool     // Protect stopped containers with an always/unless-stopped restart policy
+	ProtectActiveProjects bool     // Protect every resource of a Compose project with a running container
+	Force                 bool     // Suggest active containers and remove them with rm -f
+
+	// Active containers
+	Stop        bool          // Stop active containers before removing them
+	StopTimeout time.Duration // Grace period for docker stop before it kills
+
+	// Interactive
+	Confirm          bool // Always confirm the picker selection before deleting
+	ConfirmThreshold int  // Confirm when more than this many resources are selected (0 disables)
+
+	// Order of non-interactive output and deletion: name, size, age or type
+	Sort string
+
+	// Suggest at most this many resources, ranked by Sort or oldest first (0 means no limit)
+	MaxSuggested int
+}
+
+// DefaultProtectLabel is the label key that protects resources unless
+// other keys are configured
+const DefaultProtectLabel = "sweep.protect"
+
+// LabelSelector matches a label by key, and by value when HasValue is set
+type LabelSelector struct {
+	Key      string
+	Value    string
+	HasValue bool
 }
 
 // DefaultConfig returns the default configuration
 func DefaultConfig() *Config {
-	return &Config{}
+	return &Config{
+		ProtectLabels:        []string{DefaultProtectLabel},
+		ProtectTags:          []string{"latest"},
+		ProtectParents:       true,
+		RespectRestartPolicy: true,
+		ConfirmThreshold:     20,
+		StopTimeout:          10 * time.Second,
+	}
+}
+
+// OlderThanFor returns the --older-than of a resource type, falling back to OlderThan
+func (c *Config) OlderThanFor(resourceType string) time.Duration {
+	if d, ok := c.TypeOlderThan[resourceType]; ok {
+		return d
+	}
+	return c.OlderThan
+}
+
+// MatchName reports whether name passes the NamePattern filter
+func (c *Config) MatchName(name string) bool {
+	return c.NamePattern == nil || c.NamePattern.MatchString(name)
+}
+
+// IsProtectedByLabel reports whether any protect label is set to "true",
+// or is present at all with ProtectAnyValue
+func (c *Config) IsProtectedByLabel(labels map[string]string) bool {
+	for _, key := range c.ProtectLabels {
+		value, ok := labels[key]
+		if ok && (c.ProtectAnyValue || value == "true") {
+			return true
+		}
+	}
+	return false
+}
+
+// IsTooRecent reports whether a resource created at createdAt is younger than
+// MinAge. MinAge is a safety floor, so an unknown creation time counts as too
+// recent rather than slipping past it.
+func (c *Config) IsTooRecent(createdAt time.Time) bool {
+	return c.MinAge > 0 && (createdAt.IsZero() || time.Since(createdAt) < c.MinAge)
+}
+
+// InGracePeriod reports whether a resource of Compose project project was
+// created less than Grace ago, so `docker compose up` may not have attached
+// its containers yet. An unknown creation time is never in the grace period.
+func (c *Config) InGracePeriod(project string, createdAt time.Time) bool {
+	return c.Grace > 0 && project != "" && !createdAt.IsZero() && time.Since(createdAt) < c.Grace
+}
+
+// IsAfterUntil reports whether a resource created at createdAt is past the
+// Until cutoff. An unknown creation time is never past it.
+func (c *Config) IsAfterUntil(createdAt time.Time) bool {
+	return !c.Until.IsZero() && !createdAt.IsZero() && createdAt.After(c.Until)
+}
+
+// ParseProtectLabels validates protect label keys, ignoring surrounding spaces
+func ParseProtectLabels(keys []string) ([]string, error) {
+	var parsed []string
+	for _, key := range keys {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			return nil, fmt.Errorf("protect label key must not be empty")
+		}
+		if strings.ContainsAny(key, "= ") {
+			return nil, fmt.Errorf("invalid protect label key %q (use the key only, e.g. com.acme.keep)", key)
+		}
+		parsed = append(parsed, key)
+	}
+	return parsed, nil
+}
+
+// MatchLabels reports whether labels satisfy every LabelSelector
+func (c *Config) MatchLabels(labels map[string]string) bool {
+	for _, sel := range c.LabelSelectors {
+		value, ok := labels[sel.Key]
+		if !ok || (sel.HasValue && value != sel.Value) {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseLabelSelectors parses selectors like "env=ci" or a bare "env" (any value)
+func ParseLabelSelectors(selectors []string) ([]LabelSelector, error) {
+	var result []LabelSelector
+	for _, s := range selectors {
+		key, value, hasValue := strings.Cut(s, "=")
+		key = strings.TrimSpace(key)
+		if key == "" {
+			return nil, fmt.Errorf("invalid label selector %q (use key or key=value)", s)
+		}
+		result = append(result, LabelSelector{Key: key, Value: value, HasValue: hasValue})
+	}
+	return result, nil
+}
+
+// IsExcluded reports whether name matches any ExcludePatterns glob
+func (c *Config) IsExcluded(name string) bool {
+	for _, pattern := range c.ExcludePatterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchRepository reports whether an image repository passes the Repository
+// filter. Dangling images have no repository, so they only pass with Dangling.
+func (c *Config) MatchRepository(repository string) bool {
+	if c.Repository == "" {
+		return true
+	}
+	if repository == "" || repository == "<none>" {
+		return c.Dangling
+	}
+	ok, _ := path.Match(c.Repository, repository)
+	return ok
+}
+
+// ParseRepositoryPattern validates a --repository glob
+func ParseRepositoryPattern(pattern string) (string, error) {
+	pattern = strings.TrimSpace(pattern)
+	if _, err := path.Match(pattern, ""); err != nil {
+		return "", fmt.Errorf("invalid repository pattern %q: %w", pattern, err)
+	}
+	return pattern, nil
+}
+
+// ParseExcludePatterns validates --exclude glob patterns
+func ParseExcludePatterns(patterns []string) ([]string, error) {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid exclude pattern %q: %w", pattern, err)
+		}
+	}
+	return patterns, nil
+}
+
+// IsProtectedTag reports whether an image tag matches any ProtectTags glob.
+// Dangling images have no tag and never match.
+func (c *Config) IsProtectedTag(tag string) bool {
+	if tag == "" || tag == "<none>" {
+		return false
+	}
+	for _, pattern := range c.ProtectTags {
+		if ok, _ := path.Match(pattern, tag); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseProtectTags validates --protect-tag glob patterns. Empty patterns are
+// dropped, so a single "" clears the default list.
+func ParseProtectTags(patterns []string) ([]string, error) {
+	parsed := []string{}
+	for _, pattern := range patterns {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid protect tag pattern %q: %w", pattern, err)
+		}
+		parsed = append(parsed, pattern)
+	}
+	return parsed, nil
+}
+
+// ParseNamePattern compiles a --name regular expression. An empty pattern returns nil.
+func ParseNamePattern(s string) (*regexp.Regexp, error) {
+	if s == "" {
+		return nil, nil
+	}
+	re, err := regexp.Compile(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid name pattern %q: %w", s, err)
+	}
+	return re, nil
+}
+
+// ParseUntil parses a --until cutoff like docker's until filter: an RFC 3339
+// timestamp, a date (2006-01-02, local time), Unix seconds, or a duration
+// (see ParseDuration) counted back from now.
+func ParseUntil(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if t, err := time.Parse(time.RFC3339Nano, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return t, nil
+	}
+	if secs, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(secs, 0), nil
+	}
+	if d, err := ParseDuration(s); err == nil && d > 0 {
+		return now.Add(-d), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --until %q (use a timestamp like 2024-05-01T00:00:00Z, a date like 2024-05-01, or a duration like 7d)", s)
 }
 
 // ParseDuration parses a duration string like "7d", "24h", "1w", "30m"
@@ -65,7 +323,9 @@ func ParseDuration(s string) (time.Duration, error) {
 	}
 }
 
-// ParseSize parses a size string like "100MB", "1GB", "500KB"
+// ParseSize parses a size string like "100MB", "1GB", "500KiB".
+// KB/MB/GB/TB are decimal (1000-based) like docker reports sizes;
+// KiB/MiB/GiB/TiB are binary (1024-based).
 func ParseSize(s string) (int64, error) {
 	if s == "" {
 		return 0, nil
@@ -73,10 +333,10 @@ func ParseSize(s string) (int64, error) {
 
 	s = strings.ToUpper(strings.TrimSpace(s))
 
-	re := regexp.MustCompile(`^([\d.]+)\s*(B|KB|MB|GB|TB)?$`)
+	re := regexp.MustCompile(`^([\d.]+)\s*(B|KB|MB|GB|TB|KIB|MIB|GIB|TIB)?$`)
 	matches := re.FindStringSubmatch(s)
 	if matches == nil {
-		return 0, fmt.Errorf("invalid size: %s (use format like 100MB, 1GB)", s)
+		return 0, fmt.Errorf("invalid size: %s (use format like 100MB, 1GB, 512MiB)", s)
 	}
 
 	value, err := strconv.ParseFloat(matches[1], 64)
@@ -94,12 +354,20 @@ func ParseSize(s string) (int64, error) {
 	case "B":
 		multiplier = 1
 	case "KB":
-		multiplier = 1024
+		multiplier = 1000
 	case "MB":
-		multiplier = 1024 * 1024
+		multiplier = 1000 * 1000
 	case "GB":
-		multiplier = 1024 * 1024 * 1024
+		multiplier = 1000 * 1000 * 1000
 	case "TB":
+		multiplier = 1000 * 1000 * 1000 * 1000
+	case "KIB":
+		multiplier = 1024
+	case "MIB":
+		multiplier = 1024 * 1024
+	case "GIB":
+		multiplier = 1024 * 1024 * 1024
+	case "TIB":
 		multiplier = 1024 * 1024 * 1024 * 1024
 	}
 
@@ -0,0 +1,27 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestIsTooRecent(t *testing.T) {
+	tests := []struct {
+		name      string
+		minAge    time.Duration
+		createdAt time.Time
+		want      bool
+	}{
+		{"no floor", 0, time.Now(), false},
+		{"no floor unknown age", 0, time.Time{}, false},
+		{"younger", time.Hour, time.Now().Add(-time.Minute), true},
+		{"older", time.Hour, time.Now().Add(-2 * time.Hour), false},
+		{"unknown age", time.Hour, time.Time{}, true},
+	}
+	for _, tt := range tests {
+		cfg := &Config{MinAge: tt.minAge}
+		if got := cfg.IsTooRecent(tt.createdAt); got != tt.want {
+			t.Errorf("%s: IsTooRecent = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// fileConfig mirrors the options that can be set in the config file.
+// Pointers distinguish "not set" from zero values.
+type fileConfig struct {
+	OlderThan      *string `yaml:"older-than"`
+	NewerThan      *string `yaml:"newer-than"`
+	MinAge         *string `yaml:"min-age"`
+	Grace          *string `yaml:"grace"`
+	MinSize        *string `yaml:"min-size"`
+	MaxSize        *string `yaml:"max-size"`
+	Dangling       *bool   `yaml:"dangling"`
+	NoDangling     *bool   `yaml:"no-dangling"`
+	KeepLast       *int    `yaml:"keep-last"`
+	Exited         *bool   `yaml:"exited"`
+	Created        *bool   `yaml:"created"`
+	Anonymous      *bool   `yaml:"anonymous"`
+	Orphans        *bool   `yaml:"orphans"`
+	ProtectParents *bool   `yaml:"protect-if-child-running"`
+	RespectRestart *bool   `yaml:"respect-restart-policy"`
+
+	Confirm          *bool `yaml:"confirm"`
+	ConfirmThreshold *int  `yaml:"confirm-threshold"`
+
+	Exclude     []string `yaml:"exclude"`
+	ProtectTags []string `yaml:"protect-tags"`
+}
+
+// FilePaths returns the config file locations in lookup order:
+// 1. $XDG_CONFIG_HOME/docker-sweep/config.yaml (or ~/.config/docker-sweep/config.yaml)
+// 2. ~/.docker-sweep.yaml
+func FilePaths() []string {
+	var paths []string
+
+	home, _ := os.UserHomeDir()
+
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" && home != "" {
+		configHome = filepath.Join(home, ".config")
+	}
+	if configHome != "" {
+		paths = append(paths, filepath.Join(configHome, "docker-sweep", "config.yaml"))
+	}
+
+	if home != "" {
+		paths = append(paths, filepath.Join(home, ".docker-sweep.yaml"))
+	}
+
+	return paths
+}
+
+// Load returns the default configuration overlaid with the first config file found.
+// A missing config file is not an error.
+func Load() (*Config, error) {
+	cfg := DefaultConfig()
+	for _, path := range FilePaths() {
+		if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+		}
+		var err error
+		if cfg, err = LoadFile(path); err != nil {
+			return nil, err
+		}
+		break
+	}
+
+	// DOCKER_SWEEP_PROTECT_LABEL takes a comma-separated list of label keys
+	if env := os.Getenv("DOCKER_SWEEP_PROTECT_LABEL"); env != "" {
+		keys, err := ParseProtectLabels(strings.Split(env, ","))
+		if err != nil {
+			return nil, fmt.Errorf("invalid DOCKER_SWEEP_PROTECT_LABEL: %w", err)
+		}
+		cfg.ProtectLabels = keys
+	}
+
+	return cfg, nil
+}
+
+// LoadFile returns the default configuration overlaid with the values in path
+func LoadFile(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+
+	var fc fileConfig
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&fc); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+
+	cfg := DefaultConfig()
+	if err := fc.apply(cfg); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+
+	return cfg, nil
+}
+
+func (fc *fileConfig) apply(cfg *Config) error {
+	if fc.OlderThan != nil {
+		d, err := ParseDuration(*fc.OlderThan)
+		if err != nil {
+			return err
+		}
+		cfg.OlderThan = d
+	}
+
+	if fc.NewerThan != nil {
+		d, err := ParseDuration(*fc.NewerThan)
+		if err != nil {
+			return err
+		}
+		cfg.NewerThan = d
+	}
+
+	if fc.MinAge != nil {
+		d, err := ParseDuration(*fc.MinAge)
+		if err != nil {
+			return err
+		}
+		cfg.MinAge = d
+	}
+
+	if fc.Grace != nil {
+		d, err := ParseDuration(*fc.Grace)
+		if err != nil {
+			return err
+		}
+		cfg.Grace = d
+	}
+
+	if fc.MinSize != nil {
+		s, err := ParseSize(*fc.MinSize)
+		if err != nil {
+			return err
+		}
+		cfg.MinSize = s
+	}
+
+	if fc.MaxSize != nil {
+		s, err := ParseSize(*fc.MaxSize)
+		if err != nil {
+			return err
+		}
+		cfg.MaxSize = s
+	}
+
+	if cfg.MinSize > 0 && cfg.MaxSize > 0 && cfg.MinSize > cfg.MaxSize {
+		return fmt.Errorf("min-size must not be larger than max-size")
+	}
+
+	if fc.Dangling != nil {
+		cfg.Dangling = *fc.Dangling
+	}
+	if fc.NoDangling != nil {
+		cfg.NoDangling = *fc.NoDangling
+	}
+	if cfg.Dangling && cfg.NoDangling {
+		return fmt.Errorf("dangling and no-dangling are mutually exclusive")
+	}
+
+	if fc.KeepLast != nil {
+		if *fc.KeepLast < 0 {
+			return fmt.Errorf("keep-last must not be negative")
+		}
+		cfg.KeepLast = *fc.KeepLast
+	}
+
+	if fc.Exited != nil {
+		cfg.Exited = *fc.Exited
+	}
+	if fc.Created != nil {
+		cfg.CreatedOnly = *fc.Created
+	}
+	if cfg.Exited && cfg.CreatedOnly {
+		return fmt.Errorf("exited and created are mutually exclusive")
+	}
+	if fc.Anonymous != nil {
+		cfg.Anonymous = *fc.Anonymous
+	}
+	if fc.Orphans != nil {
+		cfg.Orphans = *fc.Orphans
+	}
+	if fc.ProtectParents != nil {
+		cfg.ProtectParents = *fc.ProtectParents
+	}
+	if fc.RespectRestart != nil {
+		cfg.RespectRestartPolicy = *fc.RespectRestart
+	}
+
+	if fc.Confirm != nil {
+		cfg.Confirm = *fc.Confirm
+	}
+	if fc.ConfirmThreshold != nil {
+		if *fc.ConfirmThreshold < 0 {
+			return fmt.Errorf("confirm-threshold must not be negative")
+		}
+		cfg.ConfirmThreshold = *fc.ConfirmThreshold
+	}
+
+	if len(fc.Exclude) > 0 {
+		patterns, err := ParseExcludePatterns(fc.Exclude)
+		if err != nil {
+			return err
+		}
+		cfg.ExcludePatterns = patterns
+	}
+
+	// An empty list disables the default protected tags
+	if fc.ProtectTags != nil {
+		patterns, err := ParseProtectTags(fc.ProtectTags)
+		if err != nil {
+			return err
+		}
+		cfg.ProtectTags = patterns
+	}
+
+	return nil
+}
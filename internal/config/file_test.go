@@ -0,0 +1,34 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadMissingFile(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, "config"))
+	t.Setenv("DOCKER_SWEEP_PROTECT_LABEL", "")
+
+	if _, err := Load(); err != nil {
+		t.Fatalf("Load without a config file: %v", err)
+	}
+}
+
+func TestLoadUnreadablePath(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("DOCKER_SWEEP_PROTECT_LABEL", "")
+
+	// docker-sweep is a file, so stat of docker-sweep/config.yaml fails with
+	// ENOTDIR rather than reporting a missing file
+	if err := os.WriteFile(filepath.Join(dir, "docker-sweep"), nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(); err == nil {
+		t.Fatal("Load skipped a config path it could not stat")
+	}
+}
@@ -0,0 +1,574 @@
+package docker
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultAPIVersion is used when the daemon does not report its API version
+const defaultAPIVersion = "1.41"
+
+// api is the Engine API backend, nil when commands go through the CLI
+var api *apiClient
+
+// apiClient talks to the Engine API over HTTP instead of shelling out to the CLI
+type apiClient struct {
+	http    *http.Client
+	baseURL string // scheme and host, e.g. http://docker
+	host    string // daemon address as configured, for display
+	version string // negotiated API version
+}
+
+// apiError is an error response from the Engine API
+type apiError struct {
+	Status  int
+	Message string
+}
+
+func (e *apiError) Error() string {
+	return e.Message
+}
+
+// InitBackend selects how commands reach the daemon from DOCKER_SWEEP_BACKEND:
+// "cli" (default) shells out to the runtime CLI, "api" talks to the Engine API
+// at DOCKER_HOST or the runtime's default socket.
+func InitBackend() error {
+	backend := strings.ToLower(strings.TrimSpace(os.Getenv("DOCKER_SWEEP_BACKEND")))
+	switch backend {
+	case "", "cli":
+		api = nil
+		return nil
+	case "api":
+		client, err := newAPIClient(apiHost())
+		if err != nil {
+			return err
+		}
+		api = client
+		return nil
+	default:
+		return fmt.Errorf("invalid DOCKER_SWEEP_BACKEND value %q (expected cli or api)", backend)
+	}
+}
+
+// apiHost returns the daemon address for the API backend
+func apiHost() string {
+	if host := os.Getenv("DOCKER_HOST"); host != "" {
+		return host
+	}
+	if cliRuntime == "podman" {
+		if host := os.Getenv("CONTAINER_HOST"); host != "" {
+			return host
+		}
+		if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" && os.Geteuid() != 0 {
+			return "unix://" + filepath.Join(dir, "podman", "podman.sock")
+		}
+		return "unix:///run/podman/podman.sock"
+	}
+	return "unix:///var/run/docker.sock"
+}
+
+func newAPIClient(host string) (*apiClient, error) {
+	u, err := url.Parse(host)
+	if err != nil {
+		return nil, fmt.Errorf("invalid daemon address %q: %w", host, err)
+	}
+
+	transport := &http.Transport{}
+	client := &apiClient{host: host, version: defaultAPIVersion}
+
+	switch u.Scheme {
+	case "unix":
+		socket := u.Path
+		transport.DialContext = func(ctx context.Context, _, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, "unix", socket)
+		}
+		client.baseURL = "http://docker"
+	case "tcp", "http", "https":
+		scheme := "http"
+		if u.Scheme == "https" || os.Getenv("DOCKER_TLS_VERIFY") != "" {
+			tlsConfig, err := apiTLSConfig()
+			if err != nil {
+				return nil, err
+			}
+			transport.TLSClientConfig = tlsConfig
+			scheme = "https"
+		}
+		client.baseURL = scheme + "://" + u.Host
+	default:
+		return nil, fmt.Errorf("daemon address %q is not supported by the API backend (use unix:// or tcp://)", host)
+	}
+
+	client.http = &http.Client{Transport: transport}
+	return client, nil
+}
+
+// apiTLSConfig loads client certificates from DOCKER_CERT_PATH (or ~/.docker)
+func apiTLSConfig() (*tls.Config, error) {
+	certPath := os.Getenv("DOCKER_CERT_PATH")
+	if certPath == "" {
+		home, _ := os.UserHomeDir()
+		certPath = filepath.Join(home, ".docker")
+	}
+
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if ca, err := os.ReadFile(filepath.Join(certPath, "ca.pem")); err == nil {
+		pool := x509.NewCertPool()
+		pool.AppendCertsFromPEM(ca)
+		cfg.RootCAs = pool
+	}
+
+	cert, err := tls.LoadX509KeyPair(filepath.Join(certPath, "cert.pem"), filepath.Join(certPath, "key.pem"))
+	if err == nil {
+		cfg.Certificates = []tls.Certificate{cert}
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to load TLS certificates from %s: %w", certPath, err)
+	}
+
+	return cfg, nil
+}
+
+// do sends a request to a versioned endpoint and decodes the JSON response into out.
+// out may be nil for endpoints without a body.
+func (c *apiClient) do(ctx context.Context, method, path string, query url.Values, out any) error {
+	return c.send(ctx, commandTimeout, method, path, query, out)
+}
+
+// send is do with a request limit other than the one set with SetTimeout
+func (c *apiClient) send(ctx context.Context, limit time.Duration, method, path string, query url.Values, out any) error {
+	ctx, cancel := withTimeout(ctx, limit)
+	defer cancel()
+
+	u := c.baseURL + "/v" + c.version + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) && limit > 0 {
+			return fmt.Errorf("%s %s: %w after %s (see --timeout)", method, path, ErrTimeout, limit)
+		}
+		return fmt.Errorf("cannot reach daemon at %s: %w", c.host, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode >= 300 {
+		var msg struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(body, &msg) != nil || msg.Message == "" {
+			msg.Message = strings.TrimSpace(string(body))
+		}
+		return &apiError{Status: resp.StatusCode, Message: msg.Message}
+	}
+
+	if out == nil || len(body) == 0 {
+		return nil
+	}
+	return json.Unmarshal(body, out)
+}
+
+// negotiate checks connectivity and adopts the daemon's API version when it is older
+func (c *apiClient) negotiate() error {
+	ctx, cancel := withTimeout(context.Background(), commandTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/version", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	var v struct {
+		Version    string `json:"Version"`
+		APIVersion string `json:"ApiVersion"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&v); err != nil {
+		return err
+	}
+	if v.APIVersion != "" && versionLess(v.APIVersion, c.version) {
+		c.version = v.APIVersion
+	}
+	runtimeVersion = VersionInfo{Runtime: cliRuntime, Server: v.Version}
+	return nil
+}
+
+// versionLess compares dotted versions such as 1.41 and 1.24, or 24.0.7 and 20.10
+func versionLess(a, b string) bool {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		x, _ := strconv.Atoi(as[i])
+		y, _ := strconv.Atoi(bs[i])
+		if x != y {
+			return x < y
+		}
+	}
+	return len(as) < len(bs)
+}
+
+// apiContainer is an entry of GET /containers/json
+type apiContainer struct {
+	ID      string            `json:"Id"`
+	Names   []string          `json:"Names"`
+	Image   string            `json:"Image"`
+	ImageID string            `json:"ImageID"`
+	State   string            `json:"State"`
+	Status  string            `json:"Status"`
+	Created int64             `json:"Created"`
+	SizeRw  int64             `json:"SizeRw"`
+	Labels  map[string]string `json:"Labels"`
+	Mounts  []struct {
+		Type   string `json:"Type"`
+		Name   string `json:"Name"`
+		Driver string `json:"Driver"`
+	} `json:"Mounts"`
+	NetworkSettings struct {
+		Networks map[string]json.RawMessage `json:"Networks"`
+	} `json:"NetworkSettings"`
+}
+
+func (c *apiClient) containers(ctx context.Context, size bool) ([]apiContainer, error) {
+	query := url.Values{"all": {"1"}}
+	if size {
+		query.Set("size", "1")
+	}
+	var containers []apiContainer
+	err := c.do(ctx, http.MethodGet, "/containers/json", query, &containers)
+	return containers, err
+}
+
+func (c *apiClient) listContainers(ctx context.Context) ([]Container, error) {
+	list, err := c.containers(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
+	containers := make([]Container, 0, len(list))
+	for _, item := range list {
+		names := make([]string, 0, len(item.Names))
+		for _, n := range item.Names {
+			names = append(names, strings.TrimPrefix(n, "/"))
+		}
+		containers = append(containers, Container{
+			ID:        item.ID,
+			Names:     strings.Join(names, ","),
+			Image:     item.Image,
+			State:     item.State,
+			Status:    item.Status,
+			CreatedAt: time.Unix(item.Created, 0),
+			Labels:    item.Labels,
+			SizeBytes: item.SizeRw,
+		})
+	}
+	return containers, nil
+}
+
+func (c *apiClient) inspectContainer(ctx context.Context, id string) (*ContainerInspect, error) {
+	var inspect ContainerInspect
+	if err := c.do(ctx, http.MethodGet, "/containers/"+url.PathEscape(id)+"/json", nil, &inspect); err != nil {
+		return nil, err
+	}
+	return &inspect, nil
+}
+
+func (c *apiClient) imagesInUse(ctx context.Context) (map[string]bool, error) {
+	list, err := c.containers(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	inUse := make(map[string]bool)
+	for _, item := range list {
+		if item.Image != "" {
+			inUse[item.Image] = true
+		}
+		if id := NormalizeImageID(item.ImageID); id != "" {
+			inUse[id] = true
+		}
+	}
+	return inUse, nil
+}
+
+func (c *apiClient) volumesInUse(ctx context.Context) (VolumeMounts, error) {
+	list, err := c.containers(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	inUse := make(VolumeMounts)
+	for _, item := range list {
+		for _, m := range item.Mounts {
+			if m.Type == "volume" && m.Name != "" {
+				inUse.add(m.Name, m.Driver)
+			}
+		}
+	}
+	return inUse, nil
+}
+
+func (c *apiClient) networksInUse(ctx context.Context) (map[string]bool, error) {
+	list, err := c.containers(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	inUse := make(map[string]bool)
+	for _, item := range list {
+		for name := range item.NetworkSettings.Networks {
+			inUse[name] = true
+		}
+	}
+	return inUse, nil
+}
+
+func (c *apiClient) composeProjectsInUse(ctx context.Context) (map[string]bool, error) {
+	list, err := c.containers(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	projects := make(map[string]bool)
+	for _, item := range list {
+		if project := ComposeProjectFromLabels(item.Labels); project != "" {
+			projects[project] = true
+		}
+	}
+	return projects, nil
+}
+
+func (c *apiClient) activeComposeProjects(ctx context.Context) (map[string]bool, error) {
+	list, err := c.containers(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	projects := make(map[string]bool)
+	for _, item := range list {
+		if item.State != "running" && item.State != "paused" && item.State != "restarting" {
+			continue
+		}
+		if project := ComposeProjectFromLabels(item.Labels); project != "" {
+			projects[project] = true
+		}
+	}
+	return projects, nil
+}
+
+// listImages returns one Image per repository:tag, like `docker images -a`
+func (c *apiClient) listImages(ctx context.Context) ([]Image, error) {
+	var list []struct {
+		ID          string            `json:"Id"`
+		RepoTags    []string          `json:"RepoTags"`
+		RepoDigests []string          `json:"RepoDigests"`
+		Created     int64             `json:"Created"`
+		Size        int64             `json:"Size"`
+		Labels      map[string]string `json:"Labels"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/images/json", url.Values{"all": {"1"}}, &list); err != nil {
+		return nil, err
+	}
+
+	var images []Image
+	for _, item := range list {
+		base := Image{
+			ID:            item.ID,
+			Repository:    "<none>",
+			Tag:           "<none>",
+			SizeBytes:     item.Size,
+			HasSize:       true,
+			CreatedAtTime: time.Unix(item.Created, 0),
+			HasCreatedAt:  true,
+			ListLabels:    item.Labels,
+			HasListLabels: true,
+		}
+		if len(item.RepoDigests) > 0 {
+			if i := strings.LastIndex(item.RepoDigests[0], "@"); i >= 0 {
+				base.Digest = item.RepoDigests[0][i+1:]
+			}
+			base.RepoDigests = item.RepoDigests
+		}
+
+		tagged := false
+		for _, ref := range item.RepoTags {
+			if ref == "<none>:<none>" {
+				continue
+			}
+			img := base
+			if i := strings.LastIndex(ref, ":"); i > strings.LastIndex(ref, "/") {
+				img.Repository, img.Tag = ref[:i], ref[i+1:]
+			} else {
+				img.Repository = ref
+			}
+			images = append(images, img)
+			tagged = true
+		}
+		if !tagged {
+			images = append(images, base)
+		}
+	}
+	return images, nil
+}
+
+func (c *apiClient) inspectImage(ctx context.Context, id string) (*ImageInspect, error) {
+	var inspect ImageInspect
+	if err := c.do(ctx, http.MethodGet, "/images/"+url.PathEscape(id)+"/json", nil, &inspect); err != nil {
+		return nil, err
+	}
+	if inspect.Labels == nil {
+		inspect.Labels = inspect.Config.Labels
+	}
+	return &inspect, nil
+}
+
+func (c *apiClient) imageHistorySizes(ctx context.Context, id string) ([]int64, error) {
+	var history []struct {
+		Size int64 `json:"Size"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/images/"+url.PathEscape(id)+"/history", nil, &history); err != nil {
+		return nil, err
+	}
+
+	sizes := make([]int64, len(history))
+	for i, h := range history {
+		// The API lists the newest entry first
+		sizes[len(history)-1-i] = h.Size
+	}
+	return sizes, nil
+}
+
+func (c *apiClient) imageLastLayerTime(ctx context.Context, id string) (time.Time, error) {
+	var history []struct {
+		Created int64 `json:"Created"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/images/"+url.PathEscape(id)+"/history", nil, &history); err != nil {
+		return time.Time{}, err
+	}
+
+	var latest time.Time
+	for _, h := range history {
+		if t := time.Unix(h.Created, 0); h.Created > 0 && t.After(latest) {
+			latest = t
+		}
+	}
+	return latest, nil
+}
+
+func (c *apiClient) listVolumes(ctx context.Context) ([]Volume, error) {
+	var resp struct {
+		Volumes []Volume `json:"Volumes"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/volumes", nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Volumes, nil
+}
+
+func (c *apiClient) inspectVolume(ctx context.Context, name string) (*VolumeInspect, error) {
+	var inspect VolumeInspect
+	if err := c.do(ctx, http.MethodGet, "/volumes/"+url.PathEscape(name), nil, &inspect); err != nil {
+		return nil, err
+	}
+	return &inspect, nil
+}
+
+func (c *apiClient) listNetworks(ctx context.Context) ([]Network, error) {
+	var networks []Network
+	err := c.do(ctx, http.MethodGet, "/networks", nil, &networks)
+	return networks, err
+}
+
+func (c *apiClient) inspectNetwork(ctx context.Context, id string) (*NetworkInspect, error) {
+	var inspect NetworkInspect
+	if err := c.do(ctx, http.MethodGet, "/networks/"+url.PathEscape(id), nil, &inspect); err != nil {
+		return nil, err
+	}
+	inspect.fillIPAM()
+	return &inspect, nil
+}
+
+func (c *apiClient) remove(resourceType, id string, force bool) error {
+	var path string
+	switch resourceType {
+	case "container":
+		path = "/containers/"
+	case "image":
+		path = "/images/"
+	case "volume":
+		path = "/volumes/"
+	case "network":
+		path = "/networks/"
+	default:
+		return fmt.Errorf("unknown resource type: %s", resourceType)
+	}
+
+	var query url.Values
+	if force && resourceType == "container" {
+		query = url.Values{"force": {"1"}}
+	}
+	return c.do(context.Background(), http.MethodDelete, path+url.PathEscape(id), query, nil)
+}
+
+// removeImage deletes an image reference; slashes in repository names stay unescaped
+func (c *apiClient) removeImage(ref string) error {
+	return c.do(context.Background(), http.MethodDelete, "/images/"+strings.ReplaceAll(url.PathEscape(ref), "%2F", "/"), nil, nil)
+}
+
+func (c *apiClient) stop(id string, timeout time.Duration) error {
+	query := url.Values{"t": {strconv.Itoa(int(timeout.Seconds()))}}
+	err := c.send(context.Background(), stopLimit(timeout), http.MethodPost, "/containers/"+url.PathEscape(id)+"/stop", query, nil)
+	if ae, ok := err.(*apiError); ok && ae.Status == http.StatusNotModified {
+		return nil // already stopped
+	}
+	return err
+}
+
+// inspectEach inspects ids one request at a time, skipping those that vanished
+func inspectEach[T any](ctx context.Context, ids []string, inspect func(context.Context, string) (*T, error), key func(*T) string) (map[string]*T, error) {
+	result := make(map[string]*T, len(ids))
+	for _, id := range ids {
+		item, err := inspect(ctx, id)
+		if err != nil {
+			if ae, ok := err.(*apiError); ok && ae.Status == http.StatusNotFound {
+				continue
+			}
+			return nil, err
+		}
+		if k := key(item); k != "" {
+			result[k] = item
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,169 @@
+package docker
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+)
+
+// ContainerCache memoizes the inspect output of all containers for one run,
+// so the in-use lookups of every resource type share a single batch of
+// `docker inspect` calls instead of each listing and inspecting containers
+// again. It is safe for concurrent use; a nil cache inspects on every call.
+// The API client lists containers in one request already and bypasses it.
+// The context of the first lookup governs the shared inspect.
+type ContainerCache struct {
+	once      sync.Once
+	inspected map[string]*ContainerInspect
+	err       error
+}
+
+// NewContainerCache returns an empty cache for one run
+func NewContainerCache() *ContainerCache {
+	return &ContainerCache{}
+}
+
+func (c *ContainerCache) all(ctx context.Context) (map[string]*ContainerInspect, error) {
+	c.once.Do(func() {
+		c.inspected, c.err = inspectAllContainers(ctx)
+	})
+	return c.inspected, c.err
+}
+
+func inspectAllContainers(ctx context.Context) (map[string]*ContainerInspect, error) {
+	out, err := Run(ctx, "ps", "-a", "--no-trunc", "--format", "{{.ID}}")
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	for _, cid := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if cid != "" {
+			ids = append(ids, cid)
+		}
+	}
+	return InspectContainers(ctx, ids)
+}
+
+// InspectContainers returns the cached inspect output of ids. Containers
+// created after the cache was filled are missing, as with a failed inspect.
+func (c *ContainerCache) InspectContainers(ctx context.Context, ids []string) (map[string]*ContainerInspect, error) {
+	if api != nil || c == nil {
+		return InspectContainers(ctx, ids)
+	}
+	all, err := c.all(ctx)
+	if errors.Is(err, ErrTimeout) {
+		return nil, err
+	}
+	if err != nil {
+		// A container removed between ps and inspect fails the whole batch
+		return InspectContainers(ctx, ids)
+	}
+
+	result := make(map[string]*ContainerInspect, len(ids))
+	for _, id := range ids {
+		if inspect, ok := all[id]; ok {
+			result[id] = inspect
+		}
+	}
+	return result, nil
+}
+
+// ImagesInUse is GetImagesInUse backed by the cache
+func (c *ContainerCache) ImagesInUse(ctx context.Context) (map[string]bool, error) {
+	if api != nil || c == nil {
+		return GetImagesInUse(ctx)
+	}
+	all, err := c.all(ctx)
+	if errors.Is(err, ErrTimeout) {
+		return nil, err
+	}
+	if err != nil {
+		return GetImagesInUse(ctx)
+	}
+
+	inUse := make(map[string]bool)
+	for _, inspect := range all {
+		for _, name := range []string{inspect.Config.Image, inspect.ImageName} {
+			if name != "" {
+				inUse[name] = true
+			}
+		}
+		if id := NormalizeImageID(inspect.Image); id != "" {
+			inUse[id] = true
+		}
+	}
+	return inUse, nil
+}
+
+// VolumesInUse is GetVolumesInUse backed by the cache
+func (c *ContainerCache) VolumesInUse(ctx context.Context) (VolumeMounts, error) {
+	if api != nil || c == nil {
+		return GetVolumesInUse(ctx)
+	}
+	all, err := c.all(ctx)
+	if errors.Is(err, ErrTimeout) {
+		return nil, err
+	}
+	if err != nil {
+		return GetVolumesInUse(ctx)
+	}
+
+	inUse := make(VolumeMounts)
+	for _, inspect := range all {
+		for _, m := range inspect.Mounts {
+			if m.Type == "volume" && m.Name != "" {
+				inUse.add(m.Name, m.Driver)
+			}
+		}
+	}
+	return inUse, nil
+}
+
+// NetworksInUse is GetNetworksInUse backed by the cache
+func (c *ContainerCache) NetworksInUse(ctx context.Context) (map[string]bool, error) {
+	if api != nil || c == nil {
+		return GetNetworksInUse(ctx)
+	}
+	all, err := c.all(ctx)
+	if errors.Is(err, ErrTimeout) {
+		return nil, err
+	}
+	if err != nil {
+		return GetNetworksInUse(ctx)
+	}
+
+	inUse := make(map[string]bool)
+	for _, inspect := range all {
+		for name := range inspect.NetworkSettings.Networks {
+			inUse[name] = true
+		}
+	}
+	return inUse, nil
+}
+
+// ActiveComposeProjects is GetActiveComposeProjects backed by the cache
+func (c *ContainerCache) ActiveComposeProjects(ctx context.Context) (map[string]bool, error) {
+	if api != nil || c == nil {
+		return GetActiveComposeProjects(ctx)
+	}
+	all, err := c.all(ctx)
+	if errors.Is(err, ErrTimeout) {
+		return nil, err
+	}
+	if err != nil {
+		return GetActiveComposeProjects(ctx)
+	}
+
+	projects := make(map[string]bool)
+	for _, inspect := range all {
+		if !inspect.State.Running {
+			continue
+		}
+		if project := ComposeProjectFromLabels(inspect.Config.Labels); project != "" {
+			projects[project] = true
+		}
+	}
+	return projects, nil
+}
@@ -2,17 +2,23 @@ package docker
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"sync"
+	"syscall"
+	"time"
 )
 
-// Protection labels - resources with these labels are protected from deletion
+// Compose labels - resources with these labels belong to a Compose project
 const (
-	LabelProtect        = "sweep.protect"              // "true" to protect
 	LabelComposeProject = "com.docker.compose.project" // Docker Compose project name
 	LabelPodmanProject  = "io.podman.compose.project"  // Podman Compose project name
 )
@@ -28,7 +34,46 @@ func ComposeProjectFromLabels(labels map[string]string) string {
 	return labels[LabelPodmanProject]
 }
 
-var cliRuntime = "docker"
+var (
+	cliRuntime     = "docker"
+	cliContext     string        // docker context (podman connection) passed to every command
+	cliHost        string        // daemon address passed to every command, overriding contexts
+	endpoint       string        // daemon described by CheckAvailable
+	runtimeVersion VersionInfo   // versions found by CheckAvailable
+	commandTimeout time.Duration // limit of each command or API request, 0 for none
+)
+
+// ErrTimeout is wrapped by the errors of commands and API requests that ran
+// past the limit set with SetTimeout
+var ErrTimeout = errors.New("operation timed out")
+
+// VersionInfo holds the client and daemon versions of the runtime
+type VersionInfo struct {
+	Runtime string // docker or podman
+	Client  string // CLI version, empty with the API backend or podman
+	Server  string // daemon version
+}
+
+// String returns the runtime and daemon version, like "docker 24.0.7"
+func (v VersionInfo) String() string {
+	version := v.Server
+	if version == "" {
+		version = v.Client
+	}
+	if version == "" {
+		return v.Runtime
+	}
+	return v.Runtime + " " + version
+}
+
+// RuntimeVersion returns the versions found by CheckAvailable, or only the
+// runtime name before it ran.
+func RuntimeVersion() VersionInfo {
+	if runtimeVersion.Runtime == "" {
+		return VersionInfo{Runtime: cliRuntime}
+	}
+	return runtimeVersion
+}
 
 // Runtime returns the currently selected container CLI runtime.
 func Runtime() string {
@@ -77,35 +122,221 @@ func probeRuntime(runtime string) bool {
 	return cmd.Run() == nil
 }
 
-// CheckAvailable checks if the selected runtime CLI is available.
+// SetContext selects the docker context (or podman connection) used by every command.
+// An empty name keeps the CLI default, which honors DOCKER_HOST.
+func SetContext(name string) {
+	cliContext = name
+}
+
+// SetTimeout limits how long each runtime command or API request may take, so
+// a wedged daemon fails the run instead of hanging it. 0 removes the limit.
+func SetTimeout(d time.Duration) {
+	commandTimeout = d
+}
+
+// withTimeout bounds ctx by limit, when there is one
+func withTimeout(ctx context.Context, limit time.Duration) (context.Context, context.CancelFunc) {
+	if limit <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, limit)
+}
+
+// stopLimit is the request limit for stopping a container, which may take
+// up to the stop timeout on its own
+func stopLimit(stopTimeout time.Duration) time.Duration {
+	if commandTimeout <= 0 {
+		return 0
+	}
+	return commandTimeout + stopTimeout
+}
+
+// SetHost selects the daemon address (unix://, tcp:// or ssh://) used by every
+// command, overriding DOCKER_HOST and contexts. An empty host keeps the default.
+func SetHost(host string) error {
+	cliHost = host
+	if api != nil && host != "" {
+		client, err := newAPIClient(host)
+		if err != nil {
+			return err
+		}
+		api = client
+	}
+	return nil
+}
+
+// Endpoint returns the daemon found by CheckAvailable, or "" before it ran.
+func Endpoint() string {
+	return endpoint
+}
+
+// globalArgs returns the CLI options that select the daemon.
+func globalArgs() []string {
+	if cliHost != "" {
+		if cliRuntime == "podman" {
+			return []string{"--url", cliHost}
+		}
+		return []string{"--host", cliHost}
+	}
+	if cliContext == "" {
+		return nil
+	}
+	if cliRuntime == "podman" {
+		return []string{"--connection", cliContext}
+	}
+	return []string{"--context", cliContext}
+}
+
+// CheckAvailable checks that the selected runtime CLI (or the Engine API when
+// DOCKER_SWEEP_BACKEND=api) is available and can reach its daemon.
 func CheckAvailable() error {
-	cmd := exec.Command(cliRuntime, "version")
-	if err := cmd.Run(); err != nil {
+	if err := checkSocket(cliHost); err != nil {
+		return err
+	}
+
+	if api != nil {
+		if cliContext != "" {
+			return fmt.Errorf("--context is not supported with DOCKER_SWEEP_BACKEND=api; set DOCKER_HOST instead")
+		}
+		if err := api.negotiate(); err != nil {
+			return fmt.Errorf("cannot connect to daemon API at %s: %w", api.host, err)
+		}
+		endpoint = api.host + " (api)"
+		return nil
+	}
+
+	if _, err := exec.LookPath(cliRuntime); err != nil {
 		return fmt.Errorf("%s is not available: %w", cliRuntime, err)
 	}
+
+	// Asking for the daemon version also checks that it is reachable
+	version := VersionInfo{Runtime: cliRuntime}
+	if cliRuntime == "podman" {
+		// podman version has no server section for local podman; info always does
+		out, err := Run(context.Background(), "info", "--format", "{{.Version.Version}}")
+		if err != nil {
+			return fmt.Errorf("cannot connect to %s daemon at %s: %w", cliRuntime, describeEndpoint(), err)
+		}
+		version.Server = strings.TrimSpace(string(out))
+	} else {
+		out, err := Run(context.Background(), "version", "--format", "{{.Client.Version}} {{.Server.Version}}")
+		if err != nil {
+			return fmt.Errorf("cannot connect to %s daemon at %s: %w", cliRuntime, describeEndpoint(), err)
+		}
+		fields := strings.Fields(string(out))
+		if len(fields) == 2 {
+			version.Client, version.Server = fields[0], fields[1]
+		}
+	}
+
+	runtimeVersion = version
+	endpoint = describeEndpoint()
 	return nil
 }
 
-// Run executes a runtime command and returns stdout.
-func Run(args ...string) ([]byte, error) {
-	cmd := exec.Command(cliRuntime, args...)
+// checkSocket fails early when host names a unix socket that is not there,
+// rather than letting the runtime report a vaguer connection error
+func checkSocket(host string) error {
+	path, ok := strings.CutPrefix(host, "unix://")
+	if !ok {
+		return nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("socket %s does not exist", path)
+		}
+		return fmt.Errorf("cannot access socket %s: %w", path, err)
+	}
+	if info.Mode()&os.ModeSocket == 0 {
+		return fmt.Errorf("%s is not a socket", path)
+	}
+	return nil
+}
+
+// describeEndpoint returns the address of the daemon commands run against
+func describeEndpoint() string {
+	if cliHost != "" {
+		return cliHost
+	}
+
+	if cliRuntime == "podman" {
+		if cliContext != "" {
+			return "connection " + cliContext
+		}
+		if host := os.Getenv("CONTAINER_HOST"); host != "" {
+			return host
+		}
+		return "local"
+	}
+
+	// DOCKER_HOST overrides the current context unless one is given explicitly
+	if cliContext == "" {
+		if host := os.Getenv("DOCKER_HOST"); host != "" {
+			return host
+		}
+	}
+
+	host := ""
+	if out, err := Run(context.Background(), "context", "inspect", "--format", "{{.Endpoints.docker.Host}}"); err == nil {
+		host = strings.TrimSpace(string(out))
+	}
+	switch {
+	case cliContext != "" && host != "":
+		return fmt.Sprintf("%s (context %s)", host, cliContext)
+	case cliContext != "":
+		return "context " + cliContext
+	case host != "":
+		return host
+	default:
+		return "default context"
+	}
+}
+
+// Run executes a runtime command and returns stdout. Cancelling ctx kills
+// the command, as does running past the limit set with SetTimeout.
+func Run(ctx context.Context, args ...string) ([]byte, error) {
+	return run(ctx, commandTimeout, args...)
+}
+
+func run(ctx context.Context, limit time.Duration, args ...string) ([]byte, error) {
+	ctx, cancel := withTimeout(ctx, limit)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, cliRuntime, append(globalArgs(), args...)...)
+	// A process group of its own keeps Ctrl+C in the terminal from killing
+	// the command mid-removal; docker-sweep decides what to stop instead
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	// Cancelling kills the whole group, and stops waiting on output pipes
+	// that a stray child may keep open
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	cmd.WaitDelay = time.Second
 	var stderr bytes.Buffer
 	cmd.Stderr = &stderr
 	out, err := cmd.Output()
 	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) && limit > 0 {
+			return nil, fmt.Errorf("%s %s: %w after %s (see --timeout)", cliRuntime, strings.Join(args, " "), ErrTimeout, limit)
+		}
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("%s %s: %w", cliRuntime, strings.Join(args, " "), ctx.Err())
+		}
 		return nil, fmt.Errorf("%s %s: %s", cliRuntime, strings.Join(args, " "), strings.TrimSpace(stderr.String()))
 	}
 	return out, nil
 }
 
 // RunJSON executes a docker command and parses JSON output (line-delimited)
-func RunJSON[T any](args ...string) ([]T, error) {
-	out, err := Run(args...)
+func RunJSON[T any](ctx context.Context, args ...string) ([]T, error) {
+	out, err := Run(ctx, args...)
 	if err != nil {
 		return nil, err
 	}
 
 	var results []T
+	var partial *PartialJSONError
 	lines := strings.Split(strings.TrimSpace(string(out)), "\n")
 	for _, line := range lines {
 		if line == "" {
@@ -113,16 +344,53 @@ func RunJSON[T any](args ...string) ([]T, error) {
 		}
 		var item T
 		if err := json.Unmarshal([]byte(line), &item); err != nil {
-			return nil, fmt.Errorf("failed to parse JSON: %w", err)
+			// Some runtimes print warnings on stdout; skip the line, not the list
+			if partial == nil {
+				partial = &PartialJSONError{Err: err}
+			}
+			partial.Skipped++
+			continue
 		}
 		results = append(results, item)
 	}
 
+	if partial != nil {
+		if len(results) == 0 {
+			return nil, fmt.Errorf("failed to parse JSON: %w", partial.Err)
+		}
+		return results, partial
+	}
 	return results, nil
 }
 
-// Remove removes a docker resource
+// PartialJSONError is returned by RunJSON, along with the parsed items, when
+// some output lines were not valid JSON and were skipped
+type PartialJSONError struct {
+	Skipped int   // Number of lines skipped
+	Err     error // Parse error of the first skipped line
+}
+
+func (e *PartialJSONError) Error() string {
+	return fmt.Sprintf("skipped %d unparseable output lines: %v", e.Skipped, e.Err)
+}
+
+func (e *PartialJSONError) Unwrap() error {
+	return e.Err
+}
+
+// IsPartialJSON reports whether err only means some output lines were skipped,
+// so the results that came with it are usable
+func IsPartialJSON(err error) bool {
+	var pe *PartialJSONError
+	return errors.As(err, &pe)
+}
+
+// Remove removes a docker resource. Removals take no context: once started
+// they are left to finish, so an interrupted sweep never stops one midway.
 func Remove(resourceType, id string) error {
+	if api != nil {
+		return api.remove(resourceType, id, false)
+	}
 	var args []string
 	switch resourceType {
 	case "container":
@@ -137,6 +405,163 @@ func Remove(resourceType, id string) error {
 		return fmt.Errorf("unknown resource type: %s", resourceType)
 	}
 
-	_, err := Run(args...)
+	_, err := Run(context.Background(), args...)
+	return err
+}
+
+// ExistsCache answers whether resources are still present from one listing
+// per resource type, taken on the first question about that type, instead of
+// parsing error messages, which may be localized. A deletion run shares one
+// so that each failed removal does not list everything again. A resource
+// that disappears after the listing still counts as present, so a removal is
+// never wrongly reported as done. It is safe for concurrent use; a nil cache
+// lists on every call.
+type ExistsCache struct {
+	mu     sync.Mutex
+	listed map[string][]string
+	errs   map[string]error
+	list   func(ctx context.Context, resourceType string) ([]string, error)
+}
+
+// NewExistsCache returns an empty cache for one deletion run
+func NewExistsCache() *ExistsCache {
+	return &ExistsCache{listed: make(map[string][]string), errs: make(map[string]error), list: listIDs}
+}
+
+// Exists reports whether the resource of resourceType with id was listed
+func (c *ExistsCache) Exists(resourceType, id string) (bool, error) {
+	var ids []string
+	var err error
+	if c == nil {
+		ids, err = listIDs(context.Background(), resourceType)
+	} else {
+		c.mu.Lock()
+		if _, ok := c.listed[resourceType]; !ok {
+			c.listed[resourceType], c.errs[resourceType] = c.list(context.Background(), resourceType)
+		}
+		ids, err = c.listed[resourceType], c.errs[resourceType]
+		c.mu.Unlock()
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if resourceType == "volume" {
+		// Volume names are matched whole
+		for _, name := range ids {
+			if name == id {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	if resourceType == "image" {
+		id = NormalizeImageID(id)
+	}
+	// IDs may be abbreviated on either side
+	for _, candidate := range ids {
+		if candidate != "" && id != "" && (strings.HasPrefix(candidate, id) || strings.HasPrefix(id, candidate)) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// listIDs returns the IDs of every resource of resourceType (names for volumes)
+func listIDs(ctx context.Context, resourceType string) ([]string, error) {
+	var ids []string
+	switch resourceType {
+	case "container":
+		if api != nil {
+			list, err := api.containers(ctx, false)
+			if err != nil {
+				return nil, err
+			}
+			for _, c := range list {
+				ids = append(ids, c.ID)
+			}
+			return ids, nil
+		}
+		out, err := Run(ctx, "ps", "-a", "-q", "--no-trunc")
+		if err != nil {
+			return nil, err
+		}
+		return strings.Fields(string(out)), nil
+	case "image":
+		images, err := ListImages(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, img := range images {
+			ids = append(ids, NormalizeImageID(img.ID))
+		}
+	case "volume":
+		volumes, err := ListVolumes(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range volumes {
+			ids = append(ids, v.Name)
+		}
+	case "network":
+		networks, err := ListNetworks(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, n := range networks {
+			ids = append(ids, n.ID)
+		}
+	default:
+		return nil, fmt.Errorf("unknown resource type: %s", resourceType)
+	}
+	return ids, nil
+}
+
+// IsNotFound reports whether err is an Engine API "not found" response
+func IsNotFound(err error) bool {
+	var apiErr *apiError
+	return errors.As(err, &apiErr) && apiErr.Status == http.StatusNotFound
+}
+
+// IsConflict reports whether err is an Engine API "conflict" response, which
+// the daemon returns for images that still have dependents
+func IsConflict(err error) bool {
+	var apiErr *apiError
+	return errors.As(err, &apiErr) && apiErr.Status == http.StatusConflict
+}
+
+// Stop stops a running container, killing it after timeout
+func Stop(id string, timeout time.Duration) error {
+	if api != nil {
+		return api.stop(id, timeout)
+	}
+	_, err := run(context.Background(), stopLimit(timeout), "stop", "--time", strconv.Itoa(int(timeout.Seconds())), id)
+	return err
+}
+
+// RemoveForce removes a resource like Remove, but stops running containers first (rm -f)
+func RemoveForce(resourceType, id string) error {
+	if api != nil {
+		return api.remove(resourceType, id, true)
+	}
+	if resourceType != "container" {
+		return Remove(resourceType, id)
+	}
+	_, err := Run(context.Background(), "rm", "-f", id)
+	return err
+}
+
+// doctorProbe names a container that is not expected to exist
+const doctorProbe = "docker-sweep-doctor-probe"
+
+// ProbeRemove asks the daemon to remove a container that does not exist, to
+// learn whether removals are allowed without removing anything. A "no such
+// container" answer means they are; permission and authorization plugin
+// errors are returned as they are.
+func ProbeRemove() error {
+	err := Remove("container", doctorProbe)
+	if err == nil || IsNotFound(err) || strings.Contains(strings.ToLower(err.Error()), "no such container") {
+		return nil
+	}
 	return err
 }
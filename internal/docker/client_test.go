@@ -0,0 +1,46 @@
+package docker
+
+import (
+	"context"
+	"testing"
+)
+
+func TestExistsCacheListsOncePerType(t *testing.T) {
+	listed := map[string]int{}
+	c := NewExistsCache()
+	c.list = func(_ context.Context, resourceType string) ([]string, error) {
+		listed[resourceType]++
+		switch resourceType {
+		case "image":
+			return []string{"aaa111"}, nil // listIDs normalizes image IDs
+		case "volume":
+			return []string{"data"}, nil
+		}
+		return nil, nil
+	}
+
+	tests := []struct {
+		resourceType string
+		id           string
+		want         bool
+	}{
+		{"image", "sha256:aaa111", true},
+		{"image", "aaa", true},
+		{"image", "sha256:bbb222", false},
+		{"volume", "data", true},
+		{"volume", "dat", false},
+		{"volume", "data2", false},
+	}
+	for _, tt := range tests {
+		got, err := c.Exists(tt.resourceType, tt.id)
+		if err != nil {
+			t.Fatalf("Exists(%q, %q): %v", tt.resourceType, tt.id, err)
+		}
+		if got != tt.want {
+			t.Errorf("Exists(%q, %q) = %v, want %v", tt.resourceType, tt.id, got, tt.want)
+		}
+	}
+	if listed["image"] != 1 || listed["volume"] != 1 {
+		t.Errorf("listed images %d and volumes %d times, want once each", listed["image"], listed["volume"])
+	}
+}
@@ -1,6 +1,7 @@
 package docker
 
 import (
+	"context"
 	"encoding/json"
 	"strings"
 	"time"
@@ -16,6 +17,9 @@ type Container struct {
 	CreatedAt time.Time         `json:"CreatedAt"`
 	Size      string            `json:"Size"`
 	Labels    map[string]string `json:"Labels"`
+
+	// SizeBytes is the writable layer size parsed from Size, 0 when unknown.
+	SizeBytes int64 `json:"-"`
 }
 
 // UnmarshalJSON supports both Docker and Podman output shapes.
@@ -31,35 +35,134 @@ func (c *Container) UnmarshalJSON(data []byte) error {
 	c.State = pickString(raw, "State", "state")
 	c.Status = pickString(raw, "Status", "status")
 	c.Size = pickString(raw, "Size", "size")
+	c.SizeBytes = parseContainerSize(pickRaw(raw, "Size", "size"))
 	c.Labels = parseLabelsRaw(pickRaw(raw, "Labels", "labels"))
 
-	createdAt := pickString(raw, "CreatedAt", "createdAt")
-	if createdAt != "" {
-		if t, err := time.Parse(time.RFC3339Nano, createdAt); err == nil {
-			c.CreatedAt = t
-		}
+	// Docker prints "2006-01-02 15:04:05 -0700 MST"; Podman's CreatedAt is
+	// relative ("2 hours ago") but Created holds a unix timestamp
+	if t, ok := parseCreatedString(pickString(raw, "CreatedAt", "createdAt")); ok {
+		c.CreatedAt = t
+	} else if t, ok := parseCreatedRaw(pickRaw(raw, "Created", "created")); ok {
+		c.CreatedAt = t
 	}
 
 	return nil
 }
 
-// ListContainers returns all containers
-func ListContainers() ([]Container, error) {
-	return RunJSON[Container]("ps", "-a", "--no-trunc", "--format", "{{json .}}")
+// parseContainerSize returns the writable layer size in bytes.
+// Docker reports "1.2MB (virtual 200MB)" where the virtual part includes the
+// shared image layers; Podman reports {"rwSize": N, "rootFsSize": M}.
+func parseContainerSize(raw json.RawMessage) int64 {
+	if len(raw) == 0 || string(raw) == "null" {
+		return 0
+	}
+
+	var podman struct {
+		RwSize int64 `json:"rwSize"`
+	}
+	if err := json.Unmarshal(raw, &podman); err == nil {
+		return podman.RwSize
+	}
+
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return 0
+	}
+	if i := strings.Index(s, "("); i >= 0 {
+		s = s[:i]
+	}
+	if n, ok := parseHumanSizeToBytes(s); ok {
+		return n
+	}
+	return 0
+}
+
+// ListContainers returns all containers, including their writable layer size
+func ListContainers(ctx context.Context) ([]Container, error) {
+	if api != nil {
+		return api.listContainers(ctx)
+	}
+	return RunJSON[Container](ctx, "ps", "-a", "--no-trunc", "--size", "--format", "{{json .}}")
+}
+
+// GetComposeProjectsInUse returns the Compose projects of all existing containers
+func GetComposeProjectsInUse(ctx context.Context) (map[string]bool, error) {
+	if api != nil {
+		return api.composeProjectsInUse(ctx)
+	}
+
+	// A skipped line could hide the last container of a project, which would
+	// then look orphaned, so partial output is an error here
+	containers, err := RunJSON[Container](ctx, "ps", "-a", "--no-trunc", "--format", "{{json .}}")
+	if err != nil {
+		return nil, err
+	}
+
+	projects := make(map[string]bool)
+	for _, c := range containers {
+		if project := ComposeProjectFromLabels(c.Labels); project != "" {
+			projects[project] = true
+		}
+	}
+	return projects, nil
+}
+
+// GetActiveComposeProjects returns the Compose projects with at least one
+// running container
+func GetActiveComposeProjects(ctx context.Context) (map[string]bool, error) {
+	if api != nil {
+		return api.activeComposeProjects(ctx)
+	}
+
+	// Without -a, ps lists running (and paused) containers only
+	containers, err := RunJSON[Container](ctx, "ps", "--no-trunc", "--format", "{{json .}}")
+	if err != nil {
+		return nil, err
+	}
+
+	projects := make(map[string]bool)
+	for _, c := range containers {
+		if project := ComposeProjectFromLabels(c.Labels); project != "" {
+			projects[project] = true
+		}
+	}
+	return projects, nil
 }
 
 // ContainerInspect holds detailed container info
 type ContainerInspect struct {
-	ID      string    `json:"Id"`
-	Created time.Time `json:"Created"`
-	Config  struct {
+	ID        string    `json:"Id"`
+	Created   time.Time `json:"Created"`
+	Image     string    `json:"Image"`     // image ID
+	ImageName string    `json:"ImageName"` // Podman only
+	State     struct {
+		Running bool `json:"Running"`
+	} `json:"State"`
+	Config struct {
+		Image  string            `json:"Image"`
 		Labels map[string]string `json:"Labels"`
 	} `json:"Config"`
+	HostConfig struct {
+		RestartPolicy struct {
+			Name string `json:"Name"`
+		} `json:"RestartPolicy"`
+	} `json:"HostConfig"`
+	Mounts []struct {
+		Type   string `json:"Type"`
+		Name   string `json:"Name"`
+		Driver string `json:"Driver"`
+	} `json:"Mounts"`
+	NetworkSettings struct {
+		Networks map[string]json.RawMessage `json:"Networks"`
+	} `json:"NetworkSettings"`
 }
 
 // InspectContainer returns detailed info about a container
-func InspectContainer(id string) (*ContainerInspect, error) {
-	out, err := Run("inspect", "--format", "{{json .}}", id)
+func InspectContainer(ctx context.Context, id string) (*ContainerInspect, error) {
+	if api != nil {
+		return api.inspectContainer(ctx, id)
+	}
+	out, err := Run(ctx, "inspect", "--format", "{{json .}}", id)
 	if err != nil {
 		return nil, err
 	}
@@ -73,7 +176,11 @@ func InspectContainer(id string) (*ContainerInspect, error) {
 }
 
 // InspectContainers inspects many containers in batches for better performance.
-func InspectContainers(ids []string) (map[string]*ContainerInspect, error) {
+func InspectContainers(ctx context.Context, ids []string) (map[string]*ContainerInspect, error) {
+	if api != nil {
+		return inspectEach(ctx, ids, api.inspectContainer, func(c *ContainerInspect) string { return c.ID })
+	}
+
 	result := make(map[string]*ContainerInspect)
 	if len(ids) == 0 {
 		return result, nil
@@ -87,7 +194,7 @@ func InspectContainers(ids []string) (map[string]*ContainerInspect, error) {
 		}
 
 		batch := ids[start:end]
-		out, err := Run(append([]string{"inspect"}, batch...)...)
+		out, err := Run(ctx, append([]string{"inspect"}, batch...)...)
 		if err != nil {
 			return nil, err
 		}
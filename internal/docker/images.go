@@ -1,8 +1,11 @@
 package docker
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -15,6 +18,7 @@ type Image struct {
 	Tag        string `json:"Tag"`
 	CreatedAt  string `json:"CreatedAt"`
 	Size       string `json:"Size"`
+	Digest     string `json:"Digest"`
 
 	// Parsed metadata from list output when available.
 	SizeBytes     int64             `json:"-"`
@@ -23,6 +27,7 @@ type Image struct {
 	HasCreatedAt  bool              `json:"-"`
 	ListLabels    map[string]string `json:"-"`
 	HasListLabels bool              `json:"-"`
+	RepoDigests   []string          `json:"-"` // repository@digest references, when the list reports them
 }
 
 // UnmarshalJSON supports both Docker and Podman output shapes.
@@ -37,6 +42,10 @@ func (i *Image) UnmarshalJSON(data []byte) error {
 	i.Tag = pickString(raw, "Tag", "tag")
 	i.CreatedAt = pickString(raw, "CreatedAt", "Created", "created")
 	i.Size = pickString(raw, "Size", "size")
+	i.Digest = pickString(raw, "Digest", "digest")
+	if i.Digest == "<none>" {
+		i.Digest = ""
+	}
 	i.ListLabels = parseLabelsRaw(pickRaw(raw, "Labels", "labels"))
 	i.HasListLabels = pickRaw(raw, "Labels", "labels") != nil
 
@@ -89,10 +98,16 @@ func parseHumanSizeToBytes(s string) (int64, bool) {
 		suffix string
 		mul    float64
 	}{
-		{"TB", 1024 * 1024 * 1024 * 1024},
-		{"GB", 1024 * 1024 * 1024},
-		{"MB", 1024 * 1024},
-		{"KB", 1024},
+		// Binary units first: "KIB" would otherwise match "B"
+		{"TIB", 1024 * 1024 * 1024 * 1024},
+		{"GIB", 1024 * 1024 * 1024},
+		{"MIB", 1024 * 1024},
+		{"KIB", 1024},
+		// Docker and Podman report decimal units (kB, MB, GB)
+		{"TB", 1000 * 1000 * 1000 * 1000},
+		{"GB", 1000 * 1000 * 1000},
+		{"MB", 1000 * 1000},
+		{"KB", 1000},
 		{"B", 1},
 	}
 
@@ -127,34 +142,44 @@ func parseCreatedRaw(raw json.RawMessage) (time.Time, bool) {
 
 	var s string
 	if err := json.Unmarshal(raw, &s); err == nil {
-		s = strings.TrimSpace(s)
-		if s == "" {
-			return time.Time{}, false
-		}
+		return parseCreatedString(s)
+	}
 
-		layouts := []string{
-			time.RFC3339Nano,
-			time.RFC3339,
-			"2006-01-02 15:04:05 -0700 MST",
-			"2006-01-02 15:04:05 -0700",
-		}
-		for _, layout := range layouts {
-			if t, err := time.Parse(layout, s); err == nil {
-				return t, true
-			}
-		}
+	return time.Time{}, false
+}
+
+// parseCreatedString parses the timestamp formats Docker and Podman print
+func parseCreatedString(s string) (time.Time, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, false
+	}
 
-		if sec, err := strconv.ParseInt(s, 10, 64); err == nil {
-			return time.Unix(sec, 0), true
+	layouts := []string{
+		time.RFC3339Nano,
+		time.RFC3339,
+		"2006-01-02 15:04:05 -0700 MST",
+		"2006-01-02 15:04:05 -0700",
+	}
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
 		}
 	}
 
+	if sec, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(sec, 0), true
+	}
+
 	return time.Time{}, false
 }
 
 // ListImages returns all images
-func ListImages() ([]Image, error) {
-	return RunJSON[Image]("images", "-a", "--no-trunc", "--format", "{{json .}}")
+func ListImages(ctx context.Context) ([]Image, error) {
+	if api != nil {
+		return api.listImages(ctx)
+	}
+	return RunJSON[Image](ctx, "images", "-a", "--digests", "--no-trunc", "--format", "{{json .}}")
 }
 
 // ImageInUse represents which containers use which images
@@ -164,9 +189,13 @@ type ImageUsage struct {
 }
 
 // GetImagesInUse returns a set of image IDs that are in use by containers
-func GetImagesInUse() (map[string]bool, error) {
+func GetImagesInUse(ctx context.Context) (map[string]bool, error) {
+	if api != nil {
+		return api.imagesInUse(ctx)
+	}
+
 	// Get all containers (including stopped) and their image names
-	out, err := Run("ps", "-a", "--format", "{{.Image}}")
+	out, err := Run(ctx, "ps", "-a", "--format", "{{.Image}}")
 	if err != nil {
 		return nil, err
 	}
@@ -180,7 +209,7 @@ func GetImagesInUse() (map[string]bool, error) {
 	}
 
 	// Also get container IDs and inspect their image IDs in one batch call
-	out, err = Run("ps", "-a", "--no-trunc", "--format", "{{.ID}}")
+	out, err = Run(ctx, "ps", "-a", "--no-trunc", "--format", "{{.ID}}")
 	if err != nil {
 		return nil, err
 	}
@@ -197,7 +226,10 @@ func GetImagesInUse() (map[string]bool, error) {
 		return inUse, nil
 	}
 
-	inspectOut, err := Run(append([]string{"inspect", "--format", "{{.Image}}"}, ids...)...)
+	inspectOut, err := Run(ctx, append([]string{"inspect", "--format", "{{.Image}}"}, ids...)...)
+	if errors.Is(err, ErrTimeout) {
+		return nil, err
+	}
 	if err != nil {
 		return inUse, nil // non-fatal, keep what we already have from image names
 	}
@@ -214,13 +246,19 @@ func GetImagesInUse() (map[string]bool, error) {
 
 // ImageInspect returns detailed info about an image
 type ImageInspect struct {
-	ID      string            `json:"Id"`
-	Size    int64             `json:"Size"`
-	Created string            `json:"Created"`
-	Labels  map[string]string `json:"Labels"`
-	Config  struct {
+	ID          string            `json:"Id"`
+	Parent      string            `json:"Parent"`
+	Size        int64             `json:"Size"`
+	Created     string            `json:"Created"`
+	RepoTags    []string          `json:"RepoTags"`
+	RepoDigests []string          `json:"RepoDigests"`
+	Labels      map[string]string `json:"Labels"`
+	Config      struct {
 		Labels map[string]string `json:"Labels"`
 	} `json:"Config"`
+	RootFS struct {
+		Layers []string `json:"Layers"` // diff IDs, oldest first
+	} `json:"RootFS"`
 }
 
 // NormalizeImageID removes known prefixes from an image ID.
@@ -230,8 +268,11 @@ func NormalizeImageID(id string) string {
 	return id
 }
 
-func InspectImage(id string) (*ImageInspect, error) {
-	out, err := Run("inspect", "--format", "{{json .}}", id)
+func InspectImage(ctx context.Context, id string) (*ImageInspect, error) {
+	if api != nil {
+		return api.inspectImage(ctx, id)
+	}
+	out, err := Run(ctx, "inspect", "--format", "{{json .}}", id)
 	if err != nil {
 		return nil, err
 	}
@@ -249,8 +290,75 @@ func InspectImage(id string) (*ImageInspect, error) {
 	return &inspect, nil
 }
 
+// ImageHistorySizes returns the bytes each history entry of an image added,
+// oldest first. Entries that did not change the filesystem report 0.
+func ImageHistorySizes(ctx context.Context, id string) ([]int64, error) {
+	if api != nil {
+		return api.imageHistorySizes(ctx, id)
+	}
+	out, err := Run(ctx, "history", "--no-trunc", "--human=false", "--format", "{{.Size}}", id)
+	if err != nil {
+		return nil, err
+	}
+
+	var sizes []int64
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if line == "" {
+			continue
+		}
+		size, ok := parseHumanSizeToBytes(line)
+		if !ok {
+			return nil, fmt.Errorf("unexpected history size %q", line)
+		}
+		sizes = append(sizes, size)
+	}
+
+	// history lists the newest entry first
+	slices.Reverse(sizes)
+	return sizes, nil
+}
+
+// ImageLastLayerTime returns the newest creation time among the history
+// entries of an image, or the zero time when history reports none.
+func ImageLastLayerTime(ctx context.Context, id string) (time.Time, error) {
+	if api != nil {
+		return api.imageLastLayerTime(ctx, id)
+	}
+	out, err := Run(ctx, "history", "--no-trunc", "--human=false", "--format", "{{.CreatedAt}}", id)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var latest time.Time
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if t, ok := parseCreatedString(line); ok && t.After(latest) {
+			latest = t
+		}
+	}
+	return latest, nil
+}
+
+// RemoveImage removes image references, such as an ID, repository:tag or
+// repository@digest. The image itself goes once no reference is left.
+func RemoveImage(refs ...string) error {
+	if api != nil {
+		for _, ref := range refs {
+			if err := api.removeImage(ref); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	_, err := Run(context.Background(), append([]string{"rmi"}, refs...)...)
+	return err
+}
+
 // InspectImages inspects many images in batches for better performance.
-func InspectImages(ids []string) (map[string]*ImageInspect, error) {
+func InspectImages(ctx context.Context, ids []string) (map[string]*ImageInspect, error) {
+	if api != nil {
+		return inspectEach(ctx, ids, api.inspectImage, func(i *ImageInspect) string { return NormalizeImageID(i.ID) })
+	}
+
 	result := make(map[string]*ImageInspect)
 	if len(ids) == 0 {
 		return result, nil
@@ -264,7 +372,7 @@ func InspectImages(ids []string) (map[string]*ImageInspect, error) {
 		}
 
 		batch := ids[start:end]
-		out, err := Run(append([]string{"inspect"}, batch...)...)
+		out, err := Run(ctx, append([]string{"inspect"}, batch...)...)
 		if err != nil {
 			return nil, err
 		}
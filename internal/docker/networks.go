@@ -1,7 +1,9 @@
 package docker
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"strings"
 )
 
@@ -29,8 +31,11 @@ func (n *Network) UnmarshalJSON(data []byte) error {
 }
 
 // ListNetworks returns all networks
-func ListNetworks() ([]Network, error) {
-	return RunJSON[Network]("network", "ls", "--no-trunc", "--format", "{{json .}}")
+func ListNetworks(ctx context.Context) ([]Network, error) {
+	if api != nil {
+		return api.listNetworks(ctx)
+	}
+	return RunJSON[Network](ctx, "network", "ls", "--no-trunc", "--format", "{{json .}}")
 }
 
 // SystemNetworks are built-in networks that should not be deleted
@@ -41,33 +46,50 @@ var SystemNetworks = map[string]bool{
 	"podman": true,
 }
 
-// GetNetworksInUse returns a set of network IDs that are in use by containers
-func GetNetworksInUse() (map[string]bool, error) {
+// GetNetworksInUse returns a set of network names that are in use by containers
+func GetNetworksInUse(ctx context.Context) (map[string]bool, error) {
+	if api != nil {
+		return api.networksInUse(ctx)
+	}
+
 	// Get all containers and their networks
-	out, err := Run("ps", "-a", "--format", "{{.ID}}")
+	out, err := Run(ctx, "ps", "-a", "--no-trunc", "--format", "{{.ID}}")
 	if err != nil {
 		return nil, err
 	}
 
 	inUse := make(map[string]bool)
 	containerIDs := strings.Split(strings.TrimSpace(string(out)), "\n")
-
+	var ids []string
 	for _, cid := range containerIDs {
-		if cid == "" {
-			continue
-		}
-		// Get networks for this container
-		netOut, err := Run("inspect", "--format", "{{json .NetworkSettings.Networks}}", cid)
-		if err != nil {
-			continue
+		if cid != "" {
+			ids = append(ids, cid)
 		}
+	}
 
-		var networks map[string]interface{}
-		if err := json.Unmarshal(netOut, &networks); err != nil {
-			continue
-		}
+	if len(ids) == 0 {
+		return inUse, nil
+	}
+
+	inspectOut, err := Run(ctx, append([]string{"inspect"}, ids...)...)
+	if errors.Is(err, ErrTimeout) {
+		return nil, err
+	}
+	if err != nil {
+		return inUse, nil // non-fatal
+	}
+
+	var containers []struct {
+		NetworkSettings struct {
+			Networks map[string]json.RawMessage `json:"Networks"`
+		} `json:"NetworkSettings"`
+	}
+	if err := json.Unmarshal(inspectOut, &containers); err != nil {
+		return inUse, nil // non-fatal
+	}
 
-		for netName := range networks {
+	for _, c := range containers {
+		for netName := range c.NetworkSettings.Networks {
 			inUse[netName] = true
 		}
 	}
@@ -82,11 +104,39 @@ type NetworkInspect struct {
 	Created string            `json:"Created"`
 	Driver  string            `json:"Driver"`
 	Labels  map[string]string `json:"Labels"`
+	IPAM    struct {
+		Config []struct {
+			Subnet  string `json:"Subnet"`
+			Gateway string `json:"Gateway"`
+		} `json:"Config"`
+	} `json:"IPAM"`
+
+	// Subnet and Gateway join the IPAM pools, e.g. "172.18.0.0/16, fd00::/64"
+	Subnet  string `json:"-"`
+	Gateway string `json:"-"`
+}
+
+// fillIPAM sets Subnet and Gateway from the IPAM config
+func (n *NetworkInspect) fillIPAM() {
+	var subnets, gateways []string
+	for _, c := range n.IPAM.Config {
+		if c.Subnet != "" {
+			subnets = append(subnets, c.Subnet)
+		}
+		if c.Gateway != "" {
+			gateways = append(gateways, c.Gateway)
+		}
+	}
+	n.Subnet = strings.Join(subnets, ", ")
+	n.Gateway = strings.Join(gateways, ", ")
 }
 
 // InspectNetwork returns detailed info about a network
-func InspectNetwork(id string) (*NetworkInspect, error) {
-	out, err := Run("network", "inspect", "--format", "{{json .}}", id)
+func InspectNetwork(ctx context.Context, id string) (*NetworkInspect, error) {
+	if api != nil {
+		return api.inspectNetwork(ctx, id)
+	}
+	out, err := Run(ctx, "network", "inspect", "--format", "{{json .}}", id)
 	if err != nil {
 		return nil, err
 	}
@@ -117,5 +167,6 @@ func InspectNetwork(id string) (*NetworkInspect, error) {
 		}
 	}
 
+	inspect.fillIPAM()
 	return &inspect, nil
 }
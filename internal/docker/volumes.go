@@ -1,7 +1,9 @@
 package docker
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"strings"
 )
 
@@ -10,6 +12,9 @@ type Volume struct {
 	Name       string `json:"Name"`
 	Driver     string `json:"Driver"`
 	Mountpoint string `json:"Mountpoint"`
+
+	// Labels from list output, for --fast which skips inspect
+	Labels map[string]string `json:"-"`
 }
 
 // UnmarshalJSON supports both Docker and Podman output shapes.
@@ -22,24 +27,54 @@ func (v *Volume) UnmarshalJSON(data []byte) error {
 	v.Name = pickString(raw, "Name", "name")
 	v.Driver = pickString(raw, "Driver", "driver")
 	v.Mountpoint = pickString(raw, "Mountpoint", "mountpoint")
+	v.Labels = parseLabelsRaw(pickRaw(raw, "Labels", "labels"))
 
 	return nil
 }
 
 // ListVolumes returns all volumes
-func ListVolumes() ([]Volume, error) {
-	return RunJSON[Volume]("volume", "ls", "--format", "{{json .}}")
+func ListVolumes(ctx context.Context) ([]Volume, error) {
+	if api != nil {
+		return api.listVolumes(ctx)
+	}
+	return RunJSON[Volume](ctx, "volume", "ls", "--format", "{{json .}}")
 }
 
-// GetVolumesInUse returns a set of volume names that are in use by containers
-func GetVolumesInUse() (map[string]bool, error) {
+// VolumeMounts records the volumes containers mount: for each volume name,
+// the drivers it is mounted from ("" when the runtime does not say)
+type VolumeMounts map[string]map[string]bool
+
+func (m VolumeMounts) add(name, driver string) {
+	if m[name] == nil {
+		m[name] = make(map[string]bool)
+	}
+	m[name][driver] = true
+}
+
+// Uses reports whether the volume name of driver is mounted. Names are only
+// unique per driver, so another driver's volume of the same name does not
+// count; a driver unknown on either side matches any driver.
+func (m VolumeMounts) Uses(name, driver string) bool {
+	drivers := m[name]
+	if len(drivers) == 0 {
+		return false
+	}
+	return driver == "" || drivers[""] || drivers[driver]
+}
+
+// GetVolumesInUse returns the volumes that are in use by containers
+func GetVolumesInUse(ctx context.Context) (VolumeMounts, error) {
+	if api != nil {
+		return api.volumesInUse(ctx)
+	}
+
 	// Get all containers and their mounts
-	out, err := Run("ps", "-a", "--no-trunc", "--format", "{{.ID}}")
+	out, err := Run(ctx, "ps", "-a", "--no-trunc", "--format", "{{.ID}}")
 	if err != nil {
 		return nil, err
 	}
 
-	inUse := make(map[string]bool)
+	inUse := make(VolumeMounts)
 	containerIDs := strings.Split(strings.TrimSpace(string(out)), "\n")
 	var ids []string
 	for _, cid := range containerIDs {
@@ -52,7 +87,10 @@ func GetVolumesInUse() (map[string]bool, error) {
 		return inUse, nil
 	}
 
-	inspectOut, err := Run(append([]string{"inspect"}, ids...)...)
+	inspectOut, err := Run(ctx, append([]string{"inspect"}, ids...)...)
+	if errors.Is(err, ErrTimeout) {
+		return nil, err
+	}
 	if err != nil {
 		return inUse, nil // non-fatal
 	}
@@ -61,6 +99,7 @@ func GetVolumesInUse() (map[string]bool, error) {
 		Mounts []struct {
 			Type   string `json:"Type"`
 			Name   string `json:"Name"`
+			Driver string `json:"Driver"`
 			Source string `json:"Source"`
 		} `json:"Mounts"`
 	}
@@ -71,7 +110,7 @@ func GetVolumesInUse() (map[string]bool, error) {
 	for _, c := range containers {
 		for _, m := range c.Mounts {
 			if m.Type == "volume" && m.Name != "" {
-				inUse[m.Name] = true
+				inUse.add(m.Name, m.Driver)
 			}
 		}
 	}
@@ -92,6 +131,28 @@ func IsAnonymousVolume(name string) bool {
 	return true
 }
 
+// IsAnonymous reports whether the inspected volume is anonymous. Docker only
+// goes by IsAnonymousVolume's name check. Podman does not always give
+// anonymous volumes a hex name, so it also counts volumes that podman volume
+// inspect flags as Anonymous or that carry an io.podman.*anonymous label.
+func IsAnonymous(name string, inspect *VolumeInspect) bool {
+	if IsAnonymousVolume(name) {
+		return true
+	}
+	if cliRuntime != "podman" || inspect == nil {
+		return false
+	}
+	if inspect.Anonymous {
+		return true
+	}
+	for key, value := range inspect.Labels {
+		if strings.HasPrefix(key, "io.podman.") && strings.HasSuffix(key, "anonymous") && value != "false" {
+			return true
+		}
+	}
+	return false
+}
+
 // VolumeInspect holds detailed volume info
 type VolumeInspect struct {
 	Name       string            `json:"Name"`
@@ -99,11 +160,15 @@ type VolumeInspect struct {
 	Mountpoint string            `json:"Mountpoint"`
 	CreatedAt  string            `json:"CreatedAt"`
 	Labels     map[string]string `json:"Labels"`
+	Anonymous  bool              `json:"Anonymous"` // podman only
 }
 
 // InspectVolume returns detailed info about a volume
-func InspectVolume(name string) (*VolumeInspect, error) {
-	out, err := Run("volume", "inspect", "--format", "{{json .}}", name)
+func InspectVolume(ctx context.Context, name string) (*VolumeInspect, error) {
+	if api != nil {
+		return api.inspectVolume(ctx, name)
+	}
+	out, err := Run(ctx, "volume", "inspect", "--format", "{{json .}}", name)
 	if err != nil {
 		return nil, err
 	}
@@ -117,7 +182,11 @@ func InspectVolume(name string) (*VolumeInspect, error) {
 }
 
 // InspectVolumes inspects many volumes in batches for better performance.
-func InspectVolumes(names []string) (map[string]*VolumeInspect, error) {
+func InspectVolumes(ctx context.Context, names []string) (map[string]*VolumeInspect, error) {
+	if api != nil {
+		return inspectEach(ctx, names, api.inspectVolume, func(v *VolumeInspect) string { return v.Name })
+	}
+
 	result := make(map[string]*VolumeInspect)
 	if len(names) == 0 {
 		return result, nil
@@ -131,7 +200,7 @@ func InspectVolumes(names []string) (map[string]*VolumeInspect, error) {
 		}
 
 		batch := names[start:end]
-		out, err := Run(append([]string{"volume", "inspect"}, batch...)...)
+		out, err := Run(ctx, append([]string{"volume", "inspect"}, batch...)...)
 		if err != nil {
 			return nil, err
 		}
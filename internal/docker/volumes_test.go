@@ -0,0 +1,29 @@
+package docker
+
+import "testing"
+
+func TestVolumeMountsUses(t *testing.T) {
+	mounts := make(VolumeMounts)
+	mounts.add("data", "local")
+	mounts.add("cache", "")
+
+	tests := []struct {
+		name   string
+		volume string
+		driver string
+		want   bool
+	}{
+		{"same driver", "data", "local", true},
+		{"other driver", "data", "nfs", false},
+		{"unknown volume driver", "data", "", true},
+		{"unknown mount driver", "cache", "nfs", true},
+		{"not mounted", "logs", "local", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mounts.Uses(tt.volume, tt.driver); got != tt.want {
+				t.Errorf("Uses(%q, %q) = %v, want %v", tt.volume, tt.driver, got, tt.want)
+			}
+		})
+	}
+}
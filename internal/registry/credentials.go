@@ -0,0 +1,111 @@
+package registry
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// dockerHubAuthKey is the key Docker Hub credentials are stored under in config.json
+const dockerHubAuthKey = "https://index.docker.io/v1/"
+
+// Credentials are a username and password (or token) for a registry
+type Credentials struct {
+	Username string
+	Secret   string
+}
+
+func (c Credentials) basic() string {
+	return base64.StdEncoding.EncodeToString([]byte(c.Username + ":" + c.Secret))
+}
+
+// dockerConfig is the subset of ~/.docker/config.json used for registry auth
+type dockerConfig struct {
+	Auths map[string]struct {
+		Auth string `json:"auth"`
+	} `json:"auths"`
+	CredsStore  string            `json:"credsStore"`
+	CredHelpers map[string]string `json:"credHelpers"`
+}
+
+// LookupCredentials returns the credentials the docker CLI would use for host,
+// from config.json or its credential helpers. Missing credentials are not an error.
+func LookupCredentials(host string) (Credentials, bool) {
+	cfg, ok := loadDockerConfig()
+	if !ok {
+		return Credentials{}, false
+	}
+
+	key := host
+	if host == dockerHubHost {
+		key = dockerHubAuthKey
+	}
+
+	if helper := cfg.CredHelpers[key]; helper != "" {
+		return helperCredentials(helper, key)
+	}
+
+	if entry, ok := cfg.Auths[key]; ok && entry.Auth != "" {
+		decoded, err := base64.StdEncoding.DecodeString(entry.Auth)
+		if err != nil {
+			return Credentials{}, false
+		}
+		user, secret, ok := strings.Cut(string(decoded), ":")
+		if !ok {
+			return Credentials{}, false
+		}
+		return Credentials{Username: user, Secret: secret}, true
+	}
+
+	if cfg.CredsStore != "" {
+		return helperCredentials(cfg.CredsStore, key)
+	}
+
+	return Credentials{}, false
+}
+
+func loadDockerConfig() (*dockerConfig, bool) {
+	dir := os.Getenv("DOCKER_CONFIG")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, false
+		}
+		dir = filepath.Join(home, ".docker")
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "config.json"))
+	if err != nil {
+		return nil, false
+	}
+
+	var cfg dockerConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, false
+	}
+	return &cfg, true
+}
+
+// helperCredentials asks docker-credential-<helper> for the credentials of key
+func helperCredentials(helper, key string) (Credentials, bool) {
+	cmd := exec.Command("docker-credential-"+helper, "get")
+	cmd.Stdin = strings.NewReader(key)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	if err := cmd.Run(); err != nil {
+		return Credentials{}, false
+	}
+
+	var resp struct {
+		Username string `json:"Username"`
+		Secret   string `json:"Secret"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &resp); err != nil || resp.Secret == "" {
+		return Credentials{}, false
+	}
+	return Credentials{Username: resp.Username, Secret: resp.Secret}, true
+}
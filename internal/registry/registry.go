@@ -0,0 +1,237 @@
+package registry
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Docker Hub endpoints
+const (
+	dockerHubHost     = "docker.io"
+	dockerHubRegistry = "registry-1.docker.io"
+)
+
+// ErrNotFound is returned when the registry does not know the repository
+var ErrNotFound = errors.New("repository not found")
+
+// Reference is a repository split into its registry host and path
+type Reference struct {
+	Host       string // registry host, e.g. docker.io or ghcr.io
+	Repository string // path inside the registry, e.g. library/nginx
+}
+
+// ParseRepository splits a local image repository (without tag) into registry and path,
+// applying Docker Hub defaults like the docker CLI does
+func ParseRepository(repo string) Reference {
+	host := dockerHubHost
+	path := repo
+
+	if i := strings.Index(repo, "/"); i >= 0 {
+		first := repo[:i]
+		if strings.ContainsAny(first, ".:") || first == "localhost" {
+			host = first
+			path = repo[i+1:]
+		}
+	}
+
+	if host == dockerHubHost && !strings.Contains(path, "/") {
+		path = "library/" + path
+	}
+
+	return Reference{Host: host, Repository: path}
+}
+
+// Client lists tags through the registry v2 API
+type Client struct {
+	http  *http.Client
+	creds func(host string) (Credentials, bool)
+}
+
+// NewClient creates a client that authenticates with docker config credentials.
+// It goes through the default transport, so HTTP_PROXY, HTTPS_PROXY and
+// NO_PROXY apply, and gives up on a registry after 10 seconds.
+func NewClient() *Client {
+	return &Client{
+		http:  &http.Client{Timeout: 10 * time.Second},
+		creds: LookupCredentials,
+	}
+}
+
+// ListTags returns every tag of ref. It returns ErrNotFound when the
+// repository does not exist (or is not visible) in the registry.
+func (c *Client) ListTags(ref Reference) ([]string, error) {
+	next := c.baseURL(ref.Host) + "/v2/" + ref.Repository + "/tags/list"
+
+	var tags []string
+	var auth string
+	for next != "" {
+		var page struct {
+			Tags []string `json:"tags"`
+		}
+		link, err := c.get(ref, next, &auth, &page)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, page.Tags...)
+		next = link
+	}
+
+	return tags, nil
+}
+
+func (c *Client) baseURL(host string) string {
+	if host == dockerHubHost {
+		host = dockerHubRegistry
+	}
+	if strings.HasPrefix(host, "localhost") || strings.HasPrefix(host, "127.0.0.1") {
+		return "http://" + host
+	}
+	return "https://" + host
+}
+
+// get fetches u into out and returns the next page URL from the Link header,
+// if any. On a 401 challenge it authenticates and stores the header in auth
+// for the following pages.
+func (c *Client) get(ref Reference, u string, auth *string, out any) (string, error) {
+	resp, err := c.do(u, *auth)
+	if err != nil {
+		return "", err
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized && *auth == "" {
+		challenge := resp.Header.Get("Www-Authenticate")
+		resp.Body.Close()
+
+		if *auth, err = c.authorize(ref, challenge); err != nil {
+			return "", err
+		}
+		if resp, err = c.do(u, *auth); err != nil {
+			return "", err
+		}
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound,
+		resp.StatusCode == http.StatusUnauthorized,
+		resp.StatusCode == http.StatusForbidden:
+		// Registries answer 401/403 for repositories that do not exist
+		return "", ErrNotFound
+	case resp.StatusCode >= 300:
+		return "", fmt.Errorf("registry %s: unexpected status %s", ref.Host, resp.Status)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return "", fmt.Errorf("registry %s: invalid tag list: %w", ref.Host, err)
+	}
+
+	return nextLink(u, resp.Header.Get("Link")), nil
+}
+
+func (c *Client) do(u, auth string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	if auth != "" {
+		req.Header.Set("Authorization", auth)
+	}
+	return c.http.Do(req)
+}
+
+// authorize answers a WWW-Authenticate challenge with an Authorization header value
+func (c *Client) authorize(ref Reference, challenge string) (string, error) {
+	creds, hasCreds := c.creds(ref.Host)
+
+	scheme, params := parseChallenge(challenge)
+	switch scheme {
+	case "basic":
+		if !hasCreds {
+			return "", ErrNotFound
+		}
+		return "Basic " + creds.basic(), nil
+
+	case "bearer":
+		q := url.Values{}
+		if service := params["service"]; service != "" {
+			q.Set("service", service)
+		}
+		q.Set("scope", "repository:"+ref.Repository+":pull")
+
+		req, err := http.NewRequest(http.MethodGet, params["realm"]+"?"+q.Encode(), nil)
+		if err != nil {
+			return "", err
+		}
+		if hasCreds {
+			req.Header.Set("Authorization", "Basic "+creds.basic())
+		}
+
+		resp, err := c.http.Do(req)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode >= 300 {
+			return "", fmt.Errorf("registry %s: token request failed: %s", ref.Host, resp.Status)
+		}
+
+		var token struct {
+			Token       string `json:"token"`
+			AccessToken string `json:"access_token"`
+		}
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return "", err
+		}
+		if err := json.Unmarshal(body, &token); err != nil {
+			return "", fmt.Errorf("registry %s: invalid token response: %w", ref.Host, err)
+		}
+		if token.Token == "" {
+			token.Token = token.AccessToken
+		}
+		return "Bearer " + token.Token, nil
+
+	default:
+		return "", fmt.Errorf("registry %s: unsupported auth challenge %q", ref.Host, challenge)
+	}
+}
+
+// parseChallenge parses `Bearer realm="...",service="..."` into a lowercase scheme and params
+func parseChallenge(header string) (string, map[string]string) {
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(header), " ")
+	params := make(map[string]string)
+	for _, part := range strings.Split(rest, ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if ok {
+			params[strings.ToLower(k)] = strings.Trim(v, `"`)
+		}
+	}
+	return strings.ToLower(scheme), params
+}
+
+// nextLink resolves the `<url>; rel="next"` pagination header against the current URL
+func nextLink(current, header string) string {
+	if header == "" || !strings.Contains(header, `rel="next"`) {
+		return ""
+	}
+	start, end := strings.Index(header, "<"), strings.Index(header, ">")
+	if start < 0 || end <= start {
+		return ""
+	}
+
+	base, err := url.Parse(current)
+	if err != nil {
+		return ""
+	}
+	next, err := base.Parse(header[start+1 : end])
+	if err != nil {
+		return ""
+	}
+	return next.String()
+}
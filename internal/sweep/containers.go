@@ -1,6 +1,8 @@
 package sweep
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -14,37 +16,42 @@ type ContainerResource struct {
 	container      docker.Container
 	category       Category
 	labels         map[string]string
+	size           int64
 	createdAt      time.Time
 	composeProject string
 	protectReason  string
+	force          bool          // remove with rm -f (active container suggested by --force)
+	stopFirst      bool          // stop before removing (active container suggested by --stop)
+	stopTimeout    time.Duration // grace period for stopFirst
 }
 
 // Implement Resource interface
-func (c *ContainerResource) ID() string             { return c.container.ID }
-func (c *ContainerResource) Type() ResourceType     { return TypeContainer }
-func (c *ContainerResource) Category() Category     { return c.category }
-func (c *ContainerResource) Size() int64            { return 0 } // Container size is complex to parse
-func (c *ContainerResource) IsProtected() bool      { return c.category == CategoryProtected }
-func (c *ContainerResource) IsSuggested() bool      { return c.category == CategorySuggested }
-func (c *ContainerResource) CreatedAt() time.Time   { return c.createdAt }
-func (c *ContainerResource) ProtectReason() string  { return c.protectReason }
-func (c *ContainerResource) ComposeProject() string { return c.composeProject }
+func (c *ContainerResource) ID() string                { return c.container.ID }
+func (c *ContainerResource) Type() ResourceType        { return TypeContainer }
+func (c *ContainerResource) Category() Category        { return c.category }
+func (c *ContainerResource) Size() int64               { return c.size } // Writable layer only
+func (c *ContainerResource) IsProtected() bool         { return c.category == CategoryProtected }
+func (c *ContainerResource) IsSuggested() bool         { return c.category == CategorySuggested }
+func (c *ContainerResource) CreatedAt() time.Time      { return c.createdAt }
+func (c *ContainerResource) ProtectReason() string     { return c.protectReason }
+func (c *ContainerResource) Labels() map[string]string { return c.labels }
+func (c *ContainerResource) ComposeProject() string    { return c.composeProject }
 
-func (c *ContainerResource) DisplayName() string {
-	name := strings.TrimPrefix(c.container.Names, "/")
-	if len(name) > 20 {
-		name = name[:17] + "..."
+// InspectSummary returns the container fields shown in the detail view
+func (c *ContainerResource) InspectSummary() []DetailField {
+	return []DetailField{
+		{Name: "Image", Value: c.container.Image},
+		{Name: "State", Value: c.container.State},
+		{Name: "Status", Value: c.container.Status},
 	}
-	return name
+}
+
+func (c *ContainerResource) DisplayName() string {
+	return truncateName(strings.TrimPrefix(c.container.Names, "/"), 20)
 }
 
 func (c *ContainerResource) Details() string {
-	state := c.container.State
-	image := c.container.Image
-	if len(image) > 25 {
-		image = image[:22] + "..."
-	}
-	return fmt.Sprintf("%s  %s", state, image)
+	return fmt.Sprintf("%s  %s", c.container.State, truncateName(c.container.Image, 25))
 }
 
 // State returns the container state
@@ -59,28 +66,57 @@ func (c *ContainerResource) Image() string {
 
 // AnalyzeContainers lists and categorizes all containers
 func AnalyzeContainers() ([]ContainerResource, error) {
-	return AnalyzeContainersWithConfig(config.DefaultConfig())
+	return AnalyzeContainersWithConfig(config.DefaultConfig(), nil, nil)
+}
+
+// AnalyzeContainersWithConfig is AnalyzeContainersContext without cancellation,
+// kept for existing callers
+func AnalyzeContainersWithConfig(cfg *config.Config, filtered *Filtered, cache *docker.ContainerCache) ([]ContainerResource, error) {
+	return AnalyzeContainersContext(context.Background(), cfg, filtered, cache)
 }
 
-// AnalyzeContainersWithConfig lists and categorizes containers with config options
-func AnalyzeContainersWithConfig(cfg *config.Config) ([]ContainerResource, error) {
-	containers, err := docker.ListContainers()
-	if err != nil {
+// AnalyzeContainersContext lists and categorizes containers with config options.
+// Resources excluded by filters are tallied in filtered, which may be nil;
+// cache shares container inspect output with the other analyses of the run
+// and may be nil too.
+// Cancelling ctx kills the runtime commands in flight and fails the analysis.
+func AnalyzeContainersContext(ctx context.Context, cfg *config.Config, filtered *Filtered, cache *docker.ContainerCache) ([]ContainerResource, error) {
+	containers, err := docker.ListContainers(ctx)
+	if err != nil && !docker.IsPartialJSON(err) {
 		return nil, err
 	}
 
+	// --fast still inspects stopped containers while their restart policy
+	// can protect them, as only inspect reports it
+	needsInspect := func(c docker.Container) bool {
+		return !cfg.Fast || cfg.RespectRestartPolicy && !isActiveState(c.State)
+	}
 	containerIDs := make([]string, 0, len(containers))
 	for _, c := range containers {
-		if c.ID != "" {
+		if c.ID != "" && needsInspect(c) {
 			containerIDs = append(containerIDs, c.ID)
 		}
 	}
 
-	inspectByID, err := docker.InspectContainers(containerIDs)
-	if err != nil {
-		inspectByID = make(map[string]*docker.ContainerInspect)
+	inspectByID := make(map[string]*docker.ContainerInspect)
+	if len(containerIDs) > 0 {
+		batchInspect, err := cache.InspectContainers(ctx, containerIDs)
+		if errors.Is(err, docker.ErrTimeout) {
+			// Falling back to one inspect per container would only time out again
+			return nil, err
+		}
+		if err == nil {
+			inspectByID = batchInspect
+		}
+	}
+
+	// Stopped services of a Compose project that is still running belong to it
+	var activeProjects map[string]bool
+	if cfg.ProtectActiveProjects {
+		activeProjects = activeComposeProjects(containers)
 	}
 
+	olderThan := cfg.OlderThanFor(string(TypeContainer))
 	var results []ContainerResource
 	for _, c := range containers {
 		labels := make(map[string]string)
@@ -89,67 +125,158 @@ func AnalyzeContainersWithConfig(cfg *config.Config) ([]ContainerResource, error
 		}
 
 		// Get detailed info for timestamp
-		var createdAt time.Time
+		createdAt := c.CreatedAt
+		var restartPolicy string
 		if inspect, ok := inspectByID[c.ID]; ok {
 			createdAt = inspect.Created
+			restartPolicy = inspect.HostConfig.RestartPolicy.Name
 			// Merge labels from inspect (more complete)
 			for k, v := range inspect.Config.Labels {
 				labels[k] = v
 			}
-		} else if inspect, err := docker.InspectContainer(c.ID); err == nil {
+		} else if !needsInspect(c) {
+			// --fast: inspect-only labels are unknown
+		} else if inspect, err := docker.InspectContainer(ctx, c.ID); err == nil {
 			createdAt = inspect.Created
+			restartPolicy = inspect.HostConfig.RestartPolicy.Name
 			for k, v := range inspect.Config.Labels {
 				labels[k] = v
 			}
+		} else if errors.Is(err, docker.ErrTimeout) {
+			return nil, err
 		}
 
 		// Get compose project if any
 		composeProject := docker.ComposeProjectFromLabels(labels)
 
 		// Categorize
-		category, protectReason := categorizeContainer(c, labels, cfg)
+		category, protectReason := categorizeContainer(c, labels, restartPolicy, createdAt, activeProjects, cfg)
+		category = promoteUnused(category, cfg)
+		active := category == CategorySuggested && isActiveState(c.State)
 
 		// Apply filters
-		if cfg.OlderThan > 0 && !createdAt.IsZero() {
-			if time.Since(createdAt) < cfg.OlderThan {
+		if olderThan > 0 && !createdAt.IsZero() {
+			if time.Since(createdAt) < olderThan {
+				filtered.Add(TypeContainer, "--older-than")
 				continue // Skip: not old enough
 			}
 		}
 
+		if cfg.NewerThan > 0 && !createdAt.IsZero() {
+			if time.Since(createdAt) > cfg.NewerThan {
+				filtered.Add(TypeContainer, "--newer-than")
+				continue // Skip: too old
+			}
+		}
+
+		if cfg.IsAfterUntil(createdAt) {
+			filtered.Add(TypeContainer, "--until")
+			continue // Skip: created after the cutoff
+		}
+
+		if !cfg.MatchName(strings.TrimPrefix(c.Names, "/")) {
+			filtered.Add(TypeContainer, "--name")
+			continue // Skip: name does not match
+		}
+
+		if !cfg.MatchLabels(labels) {
+			filtered.Add(TypeContainer, "--label")
+			continue // Skip: missing labels
+		}
+
 		if cfg.Exited && c.State != "exited" {
+			filtered.Add(TypeContainer, "--exited")
 			continue // Skip: not exited
 		}
 
+		if cfg.CreatedOnly && c.State != "created" {
+			filtered.Add(TypeContainer, "--created")
+			continue // Skip: was started at some point
+		}
+
 		results = append(results, ContainerResource{
 			container:      c,
 			category:       category,
 			labels:         labels,
+			size:           c.SizeBytes,
 			createdAt:      createdAt,
 			composeProject: composeProject,
 			protectReason:  protectReason,
+			force:          active && !cfg.Stop,
+			stopFirst:      active && cfg.Stop,
+			stopTimeout:    cfg.StopTimeout,
 		})
 	}
 
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	return results, nil
 }
 
-func categorizeContainer(c docker.Container, labels map[string]string, cfg *config.Config) (Category, string) {
+func categorizeContainer(c docker.Container, labels map[string]string, restartPolicy string, createdAt time.Time, activeProjects map[string]bool, cfg *config.Config) (Category, string) {
 	// Check protection label
-	if labels[docker.LabelProtect] == "true" {
+	if cfg.IsProtectedByLabel(labels) {
 		return CategoryProtected, "protected by label"
 	}
 
+	if cfg.IsTooRecent(createdAt) {
+		return CategoryProtected, "too recent"
+	}
+
+	if cfg.IsExcluded(strings.TrimPrefix(c.Names, "/")) {
+		return CategoryProtected, "excluded by pattern"
+	}
+
 	// Check state
+	if isActiveState(c.State) {
+		// --force and --stop may remove active containers, but never Compose-managed ones
+		if (cfg.Force || cfg.Stop) && docker.ComposeProjectFromLabels(labels) == "" {
+			return CategorySuggested, ""
+		}
+		return CategoryProtected, c.State
+	}
+
+	if isActiveProject(labels, activeProjects) {
+		return CategoryProtected, "compose project running"
+	}
+
+	// Containers that restart on their own (e.g. systemd-managed on Podman) are
+	// only stopped between boots
+	if cfg.RespectRestartPolicy && (restartPolicy == "always" || restartPolicy == "unless-stopped") {
+		return CategoryProtected, "has restart policy"
+	}
+
 	switch c.State {
-	case "running":
-		return CategoryProtected, "running"
-	case "paused":
-		return CategoryProtected, "paused"
-	case "restarting":
-		return CategoryProtected, "restarting"
 	case "exited", "dead", "created":
 		return CategorySuggested, ""
 	default:
 		return CategoryUnused, ""
 	}
 }
+
+// activeComposeProjects returns the Compose projects with an active container
+func activeComposeProjects(containers []docker.Container) map[string]bool {
+	projects := make(map[string]bool)
+	for _, c := range containers {
+		if !isActiveState(c.State) {
+			continue
+		}
+		if project := docker.ComposeProjectFromLabels(c.Labels); project != "" {
+			projects[project] = true
+		}
+	}
+	return projects
+}
+
+// isActiveProject reports whether labels place a resource in one of activeProjects
+func isActiveProject(labels map[string]string, activeProjects map[string]bool) bool {
+	project := docker.ComposeProjectFromLabels(labels)
+	return project != "" && activeProjects[project]
+}
+
+// isActiveState reports whether a container in this state must be stopped before removal
+func isActiveState(state string) bool {
+	return state == "running" || state == "paused" || state == "restarting"
+}
@@ -0,0 +1,33 @@
+package sweep
+
+import (
+	"testing"
+	"time"
+
+	"github.com/midnattsol/docker-sweep/internal/config"
+	"github.com/midnattsol/docker-sweep/internal/docker"
+)
+
+func TestForceOnlyAffectsActiveContainers(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.RespectRestartPolicy = true
+	cfg.Force = true
+
+	tests := []struct {
+		name          string
+		state         string
+		restartPolicy string
+		want          Category
+	}{
+		{"running", "running", "no", CategorySuggested},
+		{"exited with restart policy", "exited", "unless-stopped", CategoryProtected},
+		{"exited", "exited", "no", CategorySuggested},
+	}
+	for _, tt := range tests {
+		c := docker.Container{ID: "c1", Names: "web", State: tt.state}
+		got, reason := categorizeContainer(c, map[string]string{}, tt.restartPolicy, time.Time{}, nil, cfg)
+		if got != tt.want {
+			t.Errorf("%s: category = %s (%s), want %s", tt.name, got, reason, tt.want)
+		}
+	}
+}
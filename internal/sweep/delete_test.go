@@ -0,0 +1,211 @@
+package sweep
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/midnattsol/docker-sweep/internal/docker"
+)
+
+func TestIsAlreadyRemovedError(t *testing.T) {
+	tests := []struct {
+		name         string
+		resourceType ResourceType
+		msg          string
+		want         bool
+	}{
+		{"docker image", TypeImage, "Error response from daemon: No such image: sha256:aaa", true},
+		{"docker container", TypeContainer, "Error response from daemon: No such container: c1", true},
+		{"docker volume", TypeVolume, "Error response from daemon: get data: no such volume", true},
+		{"docker network", TypeNetwork, "Error response from daemon: network n1 not found", true},
+		{"podman image", TypeImage, "Error: nginx: image not known", true},
+		{"other type", TypeVolume, "Error response from daemon: No such container: c1", false},
+		{"unrelated", TypeImage, "Error response from daemon: conflict: image is being used by running container", false},
+
+		// Localized messages are left to the existence check
+		{"german", TypeImage, "Fehler: Kein solches Image: sha256:aaa", false},
+		{"french", TypeContainer, "Erreur : aucun conteneur de ce type : c1", false},
+		{"spanish", TypeVolume, "Error: no existe el volumen: data", false},
+		{"japanese", TypeNetwork, "エラー: そのようなネットワークはありません: n1", false},
+		{"chinese", TypeImage, "错误：没有这样的镜像：sha256:aaa", false},
+
+		// Localized dependency errors are not mistaken for a removal either;
+		// the image is still listed, so the deletion retries it
+		{"german dependency", TypeImage, "Fehler: Konflikt: sha256:aaa kann nicht gelöscht werden (abhängige untergeordnete Images vorhanden)", false},
+		{"french dependency", TypeImage, "Erreur : conflit : impossible de supprimer sha256:aaa (l'image a des images enfants dépendantes)", false},
+		{"japanese dependency", TypeImage, "エラー: 競合: sha256:aaa を削除できません (依存する子イメージがあります)", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isAlreadyRemovedError(tt.resourceType, errors.New(tt.msg)); got != tt.want {
+				t.Errorf("isAlreadyRemovedError(%s, %q) = %v, want %v", tt.resourceType, tt.msg, got, tt.want)
+			}
+		})
+	}
+}
+
+// fakeExists answers existence checks from a fixed set and counts them
+type fakeExists struct {
+	present map[string]bool
+	err     error
+	calls   int
+}
+
+func (f *fakeExists) Exists(resourceType, id string) (bool, error) {
+	f.calls++
+	return f.present[resourceType+"/"+id], f.err
+}
+
+func TestIsAlreadyRemovedLocalized(t *testing.T) {
+	gone := &ImageResource{image: docker.Image{ID: "sha256:aaa"}}
+	kept := &ImageResource{image: docker.Image{ID: "sha256:bbb"}}
+	localized := errors.New("Fehler: Kein solches Image")
+
+	tests := []struct {
+		name      string
+		res       Resource
+		err       error
+		exists    *fakeExists
+		want      bool
+		wantCalls int
+	}{
+		{"english skips the check", gone, errors.New("No such image: sha256:aaa"), &fakeExists{}, true, 0},
+		{"localized and gone", gone, localized, &fakeExists{present: map[string]bool{"image/sha256:bbb": true}}, true, 1},
+		{"localized and still there", kept, localized, &fakeExists{present: map[string]bool{"image/sha256:bbb": true}}, false, 1},
+		{"listing fails", gone, localized, &fakeExists{err: errors.New("daemon unreachable")}, false, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isAlreadyRemoved(tt.res, tt.err, tt.exists); got != tt.want {
+				t.Errorf("isAlreadyRemoved() = %v, want %v", got, tt.want)
+			}
+			if tt.exists.calls != tt.wantCalls {
+				t.Errorf("existence checked %d times, want %d", tt.exists.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+// fakeImageCLI puts a docker on PATH that knows one image, sha256:aaa, tagged
+// with tags. Like docker it refuses to remove the image by ID while several
+// tags reference it, and deletes it along with its last tag. It returns a
+// function listing the tags left.
+func fakeImageCLI(t *testing.T, tags ...string) func() []string {
+	t.Helper()
+	dir := t.TempDir()
+	state := filepath.Join(dir, "tags")
+	if err := os.WriteFile(state, []byte(strings.Join(tags, "\n")+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	script := `#!/bin/sh
+state=` + state + `
+case "$1" in
+inspect)
+	printf '{"Id":"sha256:aaa","RepoTags":[%s]}\n' "$(sed 's/.*/"&"/' "$state" | paste -sd, -)"
+	;;
+rmi)
+	case "$2" in
+	sha256:aaa)
+		if [ ! -s "$state" ]; then
+			echo "Error response from daemon: No such image: sha256:aaa" >&2; exit 1
+		fi
+		echo "Error response from daemon: conflict: unable to delete aaa (must be forced) - image is referenced in multiple repositories" >&2; exit 1
+		;;
+	*)
+		grep -qxF "$2" "$state" || { echo "Error response from daemon: No such image: $2" >&2; exit 1; }
+		grep -vxF "$2" "$state" > "$state.new"; mv "$state.new" "$state"
+		;;
+	esac
+	;;
+*)
+	echo "unexpected: $*" >&2; exit 1
+	;;
+esac
+`
+	if err := os.WriteFile(filepath.Join(dir, "docker"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	return func() []string {
+		data, err := os.ReadFile(state)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return strings.Fields(string(data))
+	}
+}
+
+func TestDeleteSelectedTagsOfOneImage(t *testing.T) {
+	left := fakeImageCLI(t, "app:v1", "app:v2")
+	v1 := &ImageResource{image: docker.Image{ID: "sha256:aaa", Repository: "app", Tag: "v1"}}
+	v2 := &ImageResource{image: docker.Image{ID: "sha256:aaa", Repository: "app", Tag: "v2"}}
+
+	// Both rows were selected, and the picker deduplicates its selection
+	deleted, errs := DeleteResources(context.Background(), Dedupe([]Resource{v1, v2}))
+	if deleted != 1 || len(errs) != 0 {
+		t.Fatalf("DeleteResources() = %d, %v; want 1 deleted and no errors", deleted, errs)
+	}
+	if tags := left(); len(tags) != 0 {
+		t.Errorf("tags left after deleting both: %v", tags)
+	}
+}
+
+func TestDeleteSomeTagsKeepsImage(t *testing.T) {
+	left := fakeImageCLI(t, "app:v1", "app:v2", "app:v3")
+	v1 := &ImageResource{image: docker.Image{ID: "sha256:aaa", Repository: "app", Tag: "v1"}}
+	v2 := &ImageResource{image: docker.Image{ID: "sha256:aaa", Repository: "app", Tag: "v2"}}
+
+	deleted, errs := DeleteResources(context.Background(), []Resource{v1, v2})
+	if deleted != 0 || len(errs) != 1 || !errors.Is(errs[0], errTagsKept) {
+		t.Fatalf("DeleteResources() = %d, %v; want the image reported as kept", deleted, errs)
+	}
+	if tags := left(); !slices.Equal(tags, []string{"app:v3"}) {
+		t.Errorf("tags left = %v, want only the unselected app:v3", tags)
+	}
+}
+
+func TestDeleteRetriesLocalizedDependencyErrors(t *testing.T) {
+	// parent cannot go while child exists, and says so in German
+	dir := t.TempDir()
+	for _, id := range []string{"parent", "child"} {
+		if err := os.WriteFile(filepath.Join(dir, id), nil, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	script := `#!/bin/sh
+cd ` + dir + `
+case "$1" in
+images)
+	for id in parent child; do
+		[ -e $id ] && printf '{"ID":"sha256:%s"}\n' $id
+	done
+	exit 0
+	;;
+rmi)
+	id=${2#sha256:}
+	if [ "$id" = parent ] && [ -e child ]; then
+		echo "Fehler: Konflikt: $2 kann nicht gelöscht werden (abhängige untergeordnete Images vorhanden)" >&2; exit 1
+	fi
+	rm "$id"
+	;;
+esac
+`
+	if err := os.WriteFile(filepath.Join(dir, "docker"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	parent := &ImageResource{image: docker.Image{ID: "sha256:parent", Repository: "<none>", Tag: "<none>"}}
+	child := &ImageResource{image: docker.Image{ID: "sha256:child", Repository: "<none>", Tag: "<none>"}}
+	deleted, errs := DeleteResources(context.Background(), []Resource{parent, child})
+	if deleted != 2 || len(errs) != 0 {
+		t.Fatalf("DeleteResources() = %d, %v; want both deleted on a later pass", deleted, errs)
+	}
+}
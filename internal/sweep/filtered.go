@@ -0,0 +1,67 @@
+package sweep
+
+import "sync"
+
+// Filtered tallies resources excluded by filters, keyed by the flag that excluded them.
+// A nil *Filtered discards all counts.
+type Filtered struct {
+	mu      sync.Mutex
+	counts  map[ResourceType]map[string]int
+	reasons map[ResourceType][]string
+}
+
+// FilterCount is the number of resources of a type excluded by one filter
+type FilterCount struct {
+	Filter string
+	Count  int
+}
+
+// NewFiltered creates an empty tally
+func NewFiltered() *Filtered {
+	return &Filtered{
+		counts:  make(map[ResourceType]map[string]int),
+		reasons: make(map[ResourceType][]string),
+	}
+}
+
+// Add records one resource of type t excluded by filter
+func (f *Filtered) Add(t ResourceType, filter string) {
+	if f == nil {
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.counts[t] == nil {
+		f.counts[t] = make(map[string]int)
+	}
+	if f.counts[t][filter] == 0 {
+		f.reasons[t] = append(f.reasons[t], filter)
+	}
+	f.counts[t][filter]++
+}
+
+// Counts returns the exclusions for a type in the order filters were first hit
+func (f *Filtered) Counts(t ResourceType) []FilterCount {
+	if f == nil {
+		return nil
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var counts []FilterCount
+	for _, filter := range f.reasons[t] {
+		counts = append(counts, FilterCount{Filter: filter, Count: f.counts[t][filter]})
+	}
+	return counts
+}
+
+// IsEmpty returns true if no resource was excluded
+func (f *Filtered) IsEmpty() bool {
+	if f == nil {
+		return true
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.counts) == 0
+}
@@ -0,0 +1,63 @@
+package sweep
+
+import "strings"
+
+// imageGraph tracks parent relationships between images.
+// Keys and values are normalized image IDs.
+type imageGraph struct {
+	parents map[string]string
+}
+
+func newImageGraph() *imageGraph {
+	return &imageGraph{parents: make(map[string]string)}
+}
+
+// addEdge records that child was built on top of parent.
+func (g *imageGraph) addEdge(child, parent string) {
+	if child == "" || parent == "" || child == parent {
+		return
+	}
+	g.parents[child] = parent
+}
+
+// addLayerEdges links each image to the nearest image whose layers are a
+// proper prefix of its own. BuildKit and pulled images carry no Parent, but
+// an image built FROM a local one still starts with all of its layers.
+func (g *imageGraph) addLayerEdges(layers map[string][]string) {
+	byChain := make(map[string]string, len(layers))
+	for id, l := range layers {
+		if len(l) > 0 {
+			byChain[strings.Join(l, ",")] = id
+		}
+	}
+	for id, l := range layers {
+		for n := len(l) - 1; n > 0; n-- {
+			if parent, ok := byChain[strings.Join(l[:n], ",")]; ok {
+				g.addEdge(id, parent)
+				break
+			}
+		}
+	}
+}
+
+// withChildren returns every image that another image was built on.
+func (g *imageGraph) withChildren() map[string]bool {
+	parents := make(map[string]bool, len(g.parents))
+	for _, parent := range g.parents {
+		parents[parent] = true
+	}
+	return parents
+}
+
+// ancestorsOf returns every image that is a (transitive) parent of one of ids.
+func (g *imageGraph) ancestorsOf(ids map[string]bool) map[string]bool {
+	ancestors := make(map[string]bool)
+	for id := range ids {
+		seen := map[string]bool{id: true}
+		for parent := g.parents[id]; parent != "" && !seen[parent]; parent = g.parents[parent] {
+			seen[parent] = true
+			ancestors[parent] = true
+		}
+	}
+	return ancestors
+}
@@ -1,11 +1,17 @@
 package sweep
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/midnattsol/docker-sweep/internal/config"
 	"github.com/midnattsol/docker-sweep/internal/docker"
+	"github.com/midnattsol/docker-sweep/internal/registry"
 )
 
 // ImageResource represents an analyzed image
@@ -17,17 +23,32 @@ type ImageResource struct {
 	labels        map[string]string
 	createdAt     time.Time
 	protectReason string
+	repoDigests   []string // repository@digest references
+	mergedRefs    []string // repository:tag rows folded in by Dedupe
 }
 
 // Implement Resource interface
-func (i *ImageResource) ID() string            { return i.image.ID }
-func (i *ImageResource) Type() ResourceType    { return TypeImage }
-func (i *ImageResource) Category() Category    { return i.category }
-func (i *ImageResource) Size() int64           { return i.size }
-func (i *ImageResource) IsProtected() bool     { return i.category == CategoryProtected }
-func (i *ImageResource) IsSuggested() bool     { return i.category == CategorySuggested }
-func (i *ImageResource) CreatedAt() time.Time  { return i.createdAt }
-func (i *ImageResource) ProtectReason() string { return i.protectReason }
+func (i *ImageResource) ID() string                { return i.image.ID }
+func (i *ImageResource) Type() ResourceType        { return TypeImage }
+func (i *ImageResource) Category() Category        { return i.category }
+func (i *ImageResource) Size() int64               { return i.size }
+func (i *ImageResource) IsProtected() bool         { return i.category == CategoryProtected }
+func (i *ImageResource) IsSuggested() bool         { return i.category == CategorySuggested }
+func (i *ImageResource) CreatedAt() time.Time      { return i.createdAt }
+func (i *ImageResource) ProtectReason() string     { return i.protectReason }
+func (i *ImageResource) Labels() map[string]string { return i.labels }
+
+// InspectSummary returns the image fields shown in the detail view
+func (i *ImageResource) InspectSummary() []DetailField {
+	fields := []DetailField{{Name: "Repository", Value: i.image.Repository}, {Name: "Tag", Value: i.image.Tag}}
+	if i.image.Digest != "" {
+		fields = append(fields, DetailField{Name: "Digest", Value: i.image.Digest})
+	}
+	if len(i.repoDigests) > 0 {
+		fields = append(fields, DetailField{Name: "Repo digests", Value: strings.Join(i.repoDigests, ", ")})
+	}
+	return fields
+}
 
 func (i *ImageResource) DisplayName() string {
 	if i.image.Repository == "<none>" {
@@ -41,10 +62,7 @@ func (i *ImageResource) DisplayName() string {
 	if i.image.Tag != "<none>" {
 		name += ":" + i.image.Tag
 	}
-	if len(name) > 30 {
-		name = name[:27] + "..."
-	}
-	return name
+	return truncateName(name, 30)
 }
 
 func trimImageID(id string) string {
@@ -65,9 +83,72 @@ func (i *ImageResource) Details() string {
 	} else if i.image.Repository == "<none>" {
 		status = "dangling"
 	}
+	// Re-pulled images share a repository:tag; the digest tells them apart
+	if i.image.Digest != "" {
+		status += " @" + trimImageID(i.image.Digest)
+	}
 	return status
 }
 
+// ref returns the repository:tag the image is listed under, or "" when it
+// has no tag
+func (i *ImageResource) ref() string {
+	if i.image.Repository == "<none>" || i.image.Tag == "<none>" {
+		return ""
+	}
+	return i.image.Repository + ":" + i.image.Tag
+}
+
+// tagRefs returns the repository:tag references this resource stands for:
+// those of every row of the image Dedupe merged into it, or its own
+func (i *ImageResource) tagRefs() []string {
+	if i.mergedRefs != nil {
+		return i.mergedRefs
+	}
+	if ref := i.ref(); ref != "" {
+		return []string{ref}
+	}
+	return nil
+}
+
+// removeRefs returns what to pass to rmi. Untagged images known by digest
+// are removed through each repository@digest reference, as removing them by
+// ID fails when they are referenced from several repositories.
+func (i *ImageResource) removeRefs() []string {
+	if i.image.Tag == "<none>" && len(i.repoDigests) > 0 {
+		return i.repoDigests
+	}
+	return []string{i.image.ID}
+}
+
+// inspectAncestors adds to inspected the inspect output of the images in ids
+// and of their ancestors, following Parent links one generation per batch.
+// Images already in inspected are not inspected again.
+func inspectAncestors(ctx context.Context, ids map[string]bool, inspected map[string]*docker.ImageInspect) error {
+	var pending []string
+	for id := range ids {
+		if inspected[id] == nil {
+			pending = append(pending, id)
+		}
+	}
+
+	for len(pending) > 0 {
+		batch, err := docker.InspectImages(ctx, pending)
+		if err != nil {
+			return err
+		}
+		pending = nil
+		for id, inspect := range batch {
+			inspected[id] = inspect
+			parent := docker.NormalizeImageID(inspect.Parent)
+			if parent != "" && inspected[parent] == nil && batch[parent] == nil && !slices.Contains(pending, parent) {
+				pending = append(pending, parent)
+			}
+		}
+	}
+	return nil
+}
+
 // IsDangling returns true if this is a dangling image
 func (i *ImageResource) IsDangling() bool {
 	return i.image.Repository == "<none>" && i.image.Tag == "<none>"
@@ -75,22 +156,36 @@ func (i *ImageResource) IsDangling() bool {
 
 // AnalyzeImages lists and categorizes all images
 func AnalyzeImages() ([]ImageResource, error) {
-	return AnalyzeImagesWithConfig(config.DefaultConfig())
+	return AnalyzeImagesWithConfig(config.DefaultConfig(), nil, nil)
 }
 
-// AnalyzeImagesWithConfig lists and categorizes images with config options
-func AnalyzeImagesWithConfig(cfg *config.Config) ([]ImageResource, error) {
-	images, err := docker.ListImages()
-	if err != nil {
+// AnalyzeImagesWithConfig is AnalyzeImagesContext without cancellation,
+// kept for existing callers
+func AnalyzeImagesWithConfig(cfg *config.Config, filtered *Filtered, cache *docker.ContainerCache) ([]ImageResource, error) {
+	return AnalyzeImagesContext(context.Background(), cfg, filtered, cache)
+}
+
+// AnalyzeImagesContext lists and categorizes images with config options.
+// Resources excluded by filters are tallied in filtered, which may be nil;
+// cache shares container inspect output with the other analyses of the run
+// and may be nil too.
+// Cancelling ctx kills the runtime commands in flight and fails the analysis.
+func AnalyzeImagesContext(ctx context.Context, cfg *config.Config, filtered *Filtered, cache *docker.ContainerCache) ([]ImageResource, error) {
+	images, err := docker.ListImages(ctx)
+	if err != nil && !docker.IsPartialJSON(err) {
 		return nil, err
 	}
 
-	inUse, err := docker.GetImagesInUse()
+	inUse, err := cache.ImagesInUse(ctx)
+	if errors.Is(err, docker.ErrTimeout) {
+		return nil, err
+	}
 	if err != nil {
 		// Non-fatal, continue without in-use info
 		inUse = make(map[string]bool)
 	}
 
+	olderThan := cfg.OlderThanFor(string(TypeImage))
 	inspectNeeded := make(map[string]bool)
 	imageIDs := make([]string, 0, len(images))
 	for _, img := range images {
@@ -98,17 +193,23 @@ func AnalyzeImagesWithConfig(cfg *config.Config) ([]ImageResource, error) {
 			imageIDs = append(imageIDs, id)
 
 			needsInspect := false
-			if cfg.MinSize > 0 && (!img.HasSize || img.SizeBytes == 0) {
+			if (cfg.MinSize > 0 || cfg.MaxSize > 0) && (!img.HasSize || img.SizeBytes == 0) {
+				needsInspect = true
+			}
+			if olderThan > 0 && !img.HasCreatedAt {
 				needsInspect = true
 			}
-			if cfg.OlderThan > 0 && !img.HasCreatedAt {
+			if (cfg.ProtectParents) || cfg.LeavesOnly {
+				// Parent links and layers are only available from inspect
 				needsInspect = true
 			}
-			if !img.HasListLabels {
+			if cfg.Untagged {
+				// RepoTags tells digest-only images apart from tagged ones
 				needsInspect = true
 			}
 
-			if needsInspect {
+			// Label protection needs labels even with --fast
+			if needsInspect && !cfg.Fast || !img.HasListLabels {
 				inspectNeeded[id] = true
 			}
 		}
@@ -123,34 +224,78 @@ func AnalyzeImagesWithConfig(cfg *config.Config) ([]ImageResource, error) {
 			}
 		}
 
-		if batchInspect, err := docker.InspectImages(idsToInspect); err == nil {
+		batchInspect, err := docker.InspectImages(ctx, idsToInspect)
+		if errors.Is(err, docker.ErrTimeout) {
+			return nil, err
+		}
+		if err == nil {
 			inspectByID = batchInspect
 		}
 	}
 
+	usedIDs := make(map[string]bool)
+	for _, img := range images {
+		normalizedID := docker.NormalizeImageID(img.ID)
+		if inUse[img.Repository+":"+img.Tag] || inUse[normalizedID] {
+			usedIDs[normalizedID] = true
+		}
+	}
+
+	if cfg.Fast && cfg.ProtectParents {
+		// --fast skips inspecting every image, but the parent links of the
+		// images in use are still needed to protect their ancestors
+		if err := inspectAncestors(ctx, usedIDs, inspectByID); errors.Is(err, docker.ErrTimeout) {
+			return nil, err
+		}
+	}
+
+	// Images that are ancestors of an in-use image must keep their layers
+	graph := newImageGraph()
+	for id, inspect := range inspectByID {
+		graph.addEdge(id, docker.NormalizeImageID(inspect.Parent))
+	}
+	var hasChildren map[string]bool
+	if cfg.LeavesOnly {
+		layers := make(map[string][]string, len(inspectByID))
+		for id, inspect := range inspectByID {
+			layers[id] = inspect.RootFS.Layers
+		}
+		graph.addLayerEdges(layers)
+		hasChildren = graph.withChildren()
+	}
+	parentOfInUse := graph.ancestorsOf(usedIDs)
+
 	var results []ImageResource
 	for _, img := range images {
 		// Check if in use by repository:tag or by ID
 		normalizedID := docker.NormalizeImageID(img.ID)
-		used := inUse[img.Repository+":"+img.Tag] || inUse[normalizedID]
+		used := usedIDs[normalizedID]
 
 		// Get detailed info
 		size := img.SizeBytes
 		labels := img.ListLabels
 		createdAt := img.CreatedAtTime
+		untagged := img.Tag == "<none>"
+		repoDigests := img.RepoDigests
 		if inspect, ok := inspectByID[normalizedID]; ok {
 			size = inspect.Size
 			labels = inspect.Labels
+			untagged = len(inspect.RepoTags) == 0
+			repoDigests = inspect.RepoDigests
 			if t, err := time.Parse(time.RFC3339Nano, inspect.Created); err == nil {
 				createdAt = t
 			}
 		} else if inspectNeeded[normalizedID] {
-			if inspect, err := docker.InspectImage(img.ID); err == nil {
+			if inspect, err := docker.InspectImage(ctx, img.ID); err == nil {
 				size = inspect.Size
 				labels = inspect.Labels
+				untagged = len(inspect.RepoTags) == 0
+				repoDigests = inspect.RepoDigests
 				if t, err := time.Parse(time.RFC3339Nano, inspect.Created); err == nil {
 					createdAt = t
 				}
+			} else if errors.Is(err, docker.ErrTimeout) {
+				return nil, err
 			}
 		}
 
@@ -158,6 +303,10 @@ func AnalyzeImagesWithConfig(cfg *config.Config) ([]ImageResource, error) {
 			labels = make(map[string]string)
 		}
 
+		if len(repoDigests) == 0 && img.Digest != "" && img.Repository != "<none>" {
+			repoDigests = []string{img.Repository + "@" + img.Digest}
+		}
+
 		if size == 0 && img.HasSize {
 			size = img.SizeBytes
 		}
@@ -172,20 +321,65 @@ func AnalyzeImagesWithConfig(cfg *config.Config) ([]ImageResource, error) {
 			}
 		}
 
+		// Some images carry a misleading top-level Created; the newest history
+		// entry is then a better "last touched" date for the age filters
+		if cfg.LayerAge && !cfg.Fast {
+			if t, err := docker.ImageLastLayerTime(ctx, img.ID); err == nil && !t.IsZero() {
+				createdAt = t
+			} else if errors.Is(err, docker.ErrTimeout) {
+				return nil, err
+			}
+		}
+
 		// Apply filters
-		if cfg.OlderThan > 0 && !createdAt.IsZero() {
-			if time.Since(createdAt) < cfg.OlderThan {
+		if olderThan > 0 && !createdAt.IsZero() {
+			if time.Since(createdAt) < olderThan {
+				filtered.Add(TypeImage, "--older-than")
 				continue // Skip: not old enough
 			}
 		}
 
+		if cfg.NewerThan > 0 && !createdAt.IsZero() {
+			if time.Since(createdAt) > cfg.NewerThan {
+				filtered.Add(TypeImage, "--newer-than")
+				continue // Skip: too old
+			}
+		}
+
+		if cfg.IsAfterUntil(createdAt) {
+			filtered.Add(TypeImage, "--until")
+			continue // Skip: created after the cutoff
+		}
+
+		if !cfg.MatchName(img.Repository + ":" + img.Tag) {
+			filtered.Add(TypeImage, "--name")
+			continue // Skip: name does not match
+		}
+
+		if !cfg.MatchRepository(img.Repository) {
+			filtered.Add(TypeImage, "--repository")
+			continue // Skip: other repository
+		}
+
+		if !cfg.MatchLabels(labels) {
+			filtered.Add(TypeImage, "--label")
+			continue // Skip: missing labels
+		}
+
 		if cfg.MinSize > 0 && size < cfg.MinSize {
+			filtered.Add(TypeImage, "--min-size")
 			continue // Skip: too small
 		}
 
+		if cfg.MaxSize > 0 && size > cfg.MaxSize {
+			filtered.Add(TypeImage, "--max-size")
+			continue // Skip: too large
+		}
+
 		if cfg.Dangling {
 			isDangling := img.Repository == "<none>" && img.Tag == "<none>"
 			if !isDangling {
+				filtered.Add(TypeImage, "--dangling")
 				continue // Skip: not dangling
 			}
 		}
@@ -193,11 +387,18 @@ func AnalyzeImagesWithConfig(cfg *config.Config) ([]ImageResource, error) {
 		if cfg.NoDangling {
 			isDangling := img.Repository == "<none>" && img.Tag == "<none>"
 			if isDangling {
+				filtered.Add(TypeImage, "--no-dangling")
 				continue // Skip: dangling image excluded
 			}
 		}
 
-		category, protectReason := categorizeImage(img, used, labels, cfg)
+		if cfg.Untagged && !untagged {
+			filtered.Add(TypeImage, "--untagged")
+			continue // Skip: has a tag
+		}
+
+		category, protectReason := categorizeImage(img, used, parentOfInUse[normalizedID], hasChildren[normalizedID], untagged, labels, createdAt, cfg)
+		category = promoteUnused(category, cfg)
 
 		results = append(results, ImageResource{
 			image:         img,
@@ -207,27 +408,130 @@ func AnalyzeImagesWithConfig(cfg *config.Config) ([]ImageResource, error) {
 			labels:        labels,
 			createdAt:     createdAt,
 			protectReason: protectReason,
+			repoDigests:   repoDigests,
 		})
 	}
 
+	if cfg.KeepLast > 0 {
+		applyKeepLast(results, cfg.KeepLast)
+	}
+
+	if cfg.PruneUntaggedRemote {
+		applyRemoteTags(results, registry.NewClient())
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	return results, nil
 }
 
-func categorizeImage(img docker.Image, inUse bool, labels map[string]string, cfg *config.Config) (Category, string) {
+// applyKeepLast suggests unused tagged images beyond the newest n per repository.
+// Images without a creation time count as oldest. Protected images are untouched.
+func applyKeepLast(images []ImageResource, n int) {
+	byRepo := make(map[string][]int)
+	for i := range images {
+		repo := images[i].image.Repository
+		if repo == "<none>" {
+			continue
+		}
+		byRepo[repo] = append(byRepo[repo], i)
+	}
+
+	for _, indexes := range byRepo {
+		sort.SliceStable(indexes, func(a, b int) bool {
+			ta, tb := images[indexes[a]].createdAt, images[indexes[b]].createdAt
+			if ta.IsZero() || tb.IsZero() {
+				return !ta.IsZero() && tb.IsZero()
+			}
+			return ta.After(tb)
+		})
+
+		if len(indexes) <= n {
+			continue
+		}
+		for _, i := range indexes[n:] {
+			if images[i].category == CategoryUnused {
+				images[i].category = CategorySuggested
+			}
+		}
+	}
+}
+
+// applyRemoteTags suggests unused tagged images whose tag no longer exists in
+// their registry. Repositories the registry cannot list (unreachable, unknown or
+// unauthorized) are skipped, so local-only images are never suggested.
+func applyRemoteTags(images []ImageResource, client *registry.Client) {
+	byRepo := make(map[string][]int)
+	for i := range images {
+		img := &images[i]
+		if img.image.Repository == "<none>" || img.image.Tag == "<none>" || img.category != CategoryUnused {
+			continue
+		}
+		byRepo[img.image.Repository] = append(byRepo[img.image.Repository], i)
+	}
+
+	for repo, indexes := range byRepo {
+		tags, err := client.ListTags(registry.ParseRepository(repo))
+		if err != nil {
+			continue // Skip: registry unavailable or repository unknown
+		}
+
+		remote := make(map[string]bool, len(tags))
+		for _, tag := range tags {
+			remote[tag] = true
+		}
+		for _, i := range indexes {
+			if !remote[images[i].image.Tag] {
+				images[i].category = CategorySuggested
+			}
+		}
+	}
+}
+
+func categorizeImage(img docker.Image, inUse, parentOfInUse, hasChildren, untagged bool, labels map[string]string, createdAt time.Time, cfg *config.Config) (Category, string) {
 	// Check protection label
-	if labels != nil && labels[docker.LabelProtect] == "true" {
+	if cfg.IsProtectedByLabel(labels) {
 		return CategoryProtected, "protected by label"
 	}
 
+	if cfg.IsTooRecent(createdAt) {
+		return CategoryProtected, "too recent"
+	}
+
+	if cfg.IsExcluded(img.Repository + ":" + img.Tag) {
+		return CategoryProtected, "excluded by pattern"
+	}
+
+	if cfg.IsProtectedTag(img.Tag) {
+		return CategoryProtected, "protected tag"
+	}
+
 	if inUse {
 		return CategoryProtected, "in use by container"
 	}
 
+	// Removing a parent layer would break the image chain of an in-use image
+	if parentOfInUse && cfg.ProtectParents {
+		return CategoryProtected, "parent of in-use image"
+	}
+
+	// --leaves-only keeps every image another image was built on, in use or not
+	if hasChildren && cfg.LeavesOnly {
+		return CategoryProtected, "has child images"
+	}
+
 	// Dangling images (no repo, no tag) are suggested
 	if img.Repository == "<none>" && img.Tag == "<none>" {
 		return CategorySuggested, ""
 	}
 
+	// Images only referenced by digest are suggested with --untagged
+	if cfg.Untagged && untagged {
+		return CategorySuggested, ""
+	}
+
 	// Images with tags but not in use are just "unused"
 	return CategoryUnused, ""
 }
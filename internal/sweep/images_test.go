@@ -0,0 +1,75 @@
+package sweep
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/midnattsol/docker-sweep/internal/config"
+	"github.com/midnattsol/docker-sweep/internal/docker"
+)
+
+func TestInspectAncestors(t *testing.T) {
+	// app was built on base, which was built on root; other is unrelated
+	dir := t.TempDir()
+	script := `#!/bin/sh
+[ "$1" = inspect ] || exit 1
+shift
+printf '['
+sep=
+for id in "$@"; do
+	case "$id" in
+	app) parent=sha256:base ;;
+	base) parent=sha256:root ;;
+	*) parent= ;;
+	esac
+	printf '%s{"Id":"sha256:%s","Parent":"%s"}' "$sep" "$id" "$parent"
+	sep=,
+done
+printf ']\n'
+`
+	if err := os.WriteFile(filepath.Join(dir, "docker"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	inspected := map[string]*docker.ImageInspect{}
+	if err := inspectAncestors(context.Background(), map[string]bool{"app": true}, inspected); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"app", "base", "root"} {
+		if inspected[id] == nil {
+			t.Errorf("%s was not inspected", id)
+		}
+	}
+	if inspected["other"] != nil {
+		t.Error("an image outside the chain was inspected")
+	}
+
+	graph := newImageGraph()
+	for id, inspect := range inspected {
+		graph.addEdge(id, docker.NormalizeImageID(inspect.Parent))
+	}
+	ancestors := graph.ancestorsOf(map[string]bool{"app": true})
+	if !ancestors["base"] || !ancestors["root"] || len(ancestors) != 2 {
+		t.Errorf("ancestorsOf(app) = %v, want base and root", ancestors)
+	}
+}
+
+func TestForceKeepsParentProtection(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.ProtectParents = true
+	cfg.Force = true
+
+	img := docker.Image{ID: "sha256:base", Repository: "<none>", Tag: "<none>"}
+	if got, _ := categorizeImage(img, false, true, false, true, map[string]string{}, time.Time{}, cfg); got != CategoryProtected {
+		t.Errorf("parent of an in-use image with --force: category = %s, want protected", got)
+	}
+
+	cfg.ProtectParents = false
+	if got, _ := categorizeImage(img, false, true, false, true, map[string]string{}, time.Time{}, cfg); got == CategoryProtected {
+		t.Error("--protect-if-child-running=false still protects the parent")
+	}
+}
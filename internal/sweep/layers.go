@@ -0,0 +1,142 @@
+package sweep
+
+import (
+	"context"
+
+	"github.com/midnattsol/docker-sweep/internal/docker"
+)
+
+// layerIndex records the layers of every local image and the size of each layer
+type layerIndex struct {
+	images map[string][]string // normalized image ID -> layer diff IDs, oldest first
+	sizes  map[string]int64    // layer diff ID -> bytes, for layers with a known size
+}
+
+// IndexLayers inspects the layers of every local image, including those the
+// analysis filtered out, so ReclaimableSize can leave out layers that images
+// being kept still use. It is slow: every image is inspected.
+func (r *Result) IndexLayers() error {
+	return r.IndexLayersContext(context.Background())
+}
+
+// IndexLayersContext is IndexLayers with a context that cancels the inspects
+func (r *Result) IndexLayersContext(ctx context.Context) error {
+	images, err := docker.ListImages(ctx)
+	if err != nil && !docker.IsPartialJSON(err) {
+		return err
+	}
+
+	seen := make(map[string]bool)
+	var ids []string
+	for _, img := range images {
+		id := docker.NormalizeImageID(img.ID)
+		if id != "" && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	inspected, err := docker.InspectImages(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	idx := &layerIndex{
+		images: make(map[string][]string, len(inspected)),
+		sizes:  make(map[string]int64),
+	}
+	for id, inspect := range inspected {
+		layers := inspect.RootFS.Layers
+		idx.images[docker.NormalizeImageID(id)] = layers
+
+		history, err := docker.ImageHistorySizes(ctx, id)
+		if err != nil {
+			continue // Layer sizes stay unknown; the image counts whole
+		}
+
+		// Every history entry that changed the filesystem created one layer, in
+		// order. Empty layers make the pairing ambiguous, so skip the image then.
+		var sizes []int64
+		for _, size := range history {
+			if size > 0 {
+				sizes = append(sizes, size)
+			}
+		}
+		if len(sizes) != len(layers) {
+			continue
+		}
+		for i, layer := range layers {
+			idx.sizes[layer] = sizes[i]
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	r.layers = idx
+	return nil
+}
+
+// ReclaimableSize returns the bytes deleting selected frees. After
+// IndexLayers, image layers that kept images still use are not counted and
+// layers shared by selected images are counted once. Otherwise, and for
+// images whose layer sizes are unknown, it sums the resource sizes.
+func (r *Result) ReclaimableSize(selected []Resource) int64 {
+	selected = Dedupe(selected)
+
+	var total int64
+	var images []Resource
+	for _, res := range selected {
+		if res.Type() == TypeImage && r.layers != nil {
+			images = append(images, res)
+			continue
+		}
+		total += res.Size()
+	}
+	if len(images) == 0 {
+		return total
+	}
+
+	deleting := make(map[string]bool, len(images))
+	for _, img := range images {
+		deleting[docker.NormalizeImageID(img.ID())] = true
+	}
+
+	retained := make(map[string]bool)
+	for id, layers := range r.layers.images {
+		if deleting[id] {
+			continue
+		}
+		for _, layer := range layers {
+			retained[layer] = true
+		}
+	}
+
+	counted := make(map[string]bool)
+	for _, img := range images {
+		layers, ok := r.layers.images[docker.NormalizeImageID(img.ID())]
+		if !ok || !r.layers.knowsSizes(layers) {
+			total += img.Size()
+			continue
+		}
+		for _, layer := range layers {
+			if retained[layer] || counted[layer] {
+				continue
+			}
+			counted[layer] = true
+			total += r.layers.sizes[layer]
+		}
+	}
+
+	return total
+}
+
+// knowsSizes reports whether the size of every layer is known
+func (idx *layerIndex) knowsSizes(layers []string) bool {
+	for _, layer := range layers {
+		if _, ok := idx.sizes[layer]; !ok {
+			return false
+		}
+	}
+	return true
+}
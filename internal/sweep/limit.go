@@ -0,0 +1,44 @@
+package sweep
+
+// LimitSuggested keeps the first n suggested resources and demotes the rest
+// to unused, so the picker leaves them unselected and --yes skips them.
+// Resources are ranked by r.Sort, or oldest first when it is empty, which
+// makes repeated runs work through a large backlog in a stable order.
+// n <= 0 keeps every suggestion.
+func (r *Result) LimitSuggested(n int) {
+	if n <= 0 {
+		return
+	}
+
+	ranked := Dedupe(r.suggestedUnsorted())
+	key := r.Sort
+	if key == "" {
+		key = SortAge
+	}
+	SortResources(ranked, key)
+	if len(ranked) <= n {
+		return
+	}
+
+	keep := make(map[string]bool, n)
+	for _, res := range ranked[:n] {
+		keep[resourceKey(res)] = true
+	}
+	demote := func(res Resource, category *Category) {
+		if *category == CategorySuggested && !keep[resourceKey(res)] {
+			*category = CategoryUnused
+		}
+	}
+	for i := range r.Containers {
+		demote(&r.Containers[i], &r.Containers[i].category)
+	}
+	for i := range r.Images {
+		demote(&r.Images[i], &r.Images[i].category)
+	}
+	for i := range r.Volumes {
+		demote(&r.Volumes[i], &r.Volumes[i].category)
+	}
+	for i := range r.Networks {
+		demote(&r.Networks[i], &r.Networks[i].category)
+	}
+}
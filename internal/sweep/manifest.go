@@ -0,0 +1,68 @@
+package sweep
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+)
+
+// manifestResource is a lightweight Resource rebuilt from a manifest Record.
+// It carries just enough to delete the resource without re-analyzing.
+type manifestResource struct {
+	record Record
+}
+
+func (r *manifestResource) ID() string          { return r.record.ID }
+func (r *manifestResource) Type() ResourceType  { return r.record.Type }
+func (r *manifestResource) DisplayName() string { return r.record.Name }
+func (r *manifestResource) Category() Category  { return CategorySuggested }
+func (r *manifestResource) Details() string     { return "" }
+func (r *manifestResource) Size() int64         { return r.record.Size }
+func (r *manifestResource) IsProtected() bool   { return false }
+func (r *manifestResource) IsSuggested() bool   { return true }
+func (r *manifestResource) tagRefs() []string   { return r.record.Refs }
+
+// ReadManifest parses a dry-run Report (as printed by --dry-run --output json)
+// and returns the resources it would delete
+func ReadManifest(r io.Reader) ([]Resource, error) {
+	var report Report
+	if err := json.NewDecoder(r).Decode(&report); err != nil {
+		return nil, fmt.Errorf("invalid manifest: %w", err)
+	}
+	if !report.DryRun {
+		return nil, fmt.Errorf("invalid manifest: not a dry-run report")
+	}
+
+	resources := make([]Resource, 0, len(report.Deleted))
+	images := make(map[string]*manifestResource)
+	for _, rec := range report.Deleted {
+		switch rec.Type {
+		case TypeContainer, TypeImage, TypeVolume, TypeNetwork:
+		default:
+			return nil, fmt.Errorf("invalid manifest: unknown resource type %q", rec.Type)
+		}
+		if rec.ID == "" {
+			return nil, fmt.Errorf("invalid manifest: %s without id", rec.Type)
+		}
+		if rec.Name == "" {
+			rec.Name = rec.ID
+		}
+		// Records of one image are merged so that all of their tags are removed
+		if prev := images[rec.ID]; rec.Type == TypeImage && prev != nil {
+			for _, ref := range rec.Refs {
+				if !slices.Contains(prev.record.Refs, ref) {
+					prev.record.Refs = append(prev.record.Refs, ref)
+				}
+			}
+			continue
+		}
+		res := &manifestResource{record: rec}
+		if rec.Type == TypeImage {
+			images[rec.ID] = res
+		}
+		resources = append(resources, res)
+	}
+
+	return Dedupe(resources), nil
+}
@@ -0,0 +1,54 @@
+package sweep
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/midnattsol/docker-sweep/internal/docker"
+)
+
+func TestApplyManifestRemovesRecordedTags(t *testing.T) {
+	left := fakeImageCLI(t, "app:v1", "app:v2")
+	v1 := &ImageResource{image: docker.Image{ID: "sha256:aaa", Repository: "app", Tag: "v1"}}
+	v2 := &ImageResource{image: docker.Image{ID: "sha256:aaa", Repository: "app", Tag: "v2"}}
+
+	var plan bytes.Buffer
+	if err := json.NewEncoder(&plan).Encode(NewDryRunReport(Dedupe([]Resource{v1, v2}))); err != nil {
+		t.Fatal(err)
+	}
+	resources, err := ReadManifest(&plan)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deleted, errs := DeleteResources(context.Background(), resources)
+	if deleted != 1 || len(errs) != 0 {
+		t.Fatalf("DeleteResources() = %d, %v; want 1 deleted and no errors", deleted, errs)
+	}
+	if tags := left(); len(tags) != 0 {
+		t.Errorf("tags left after applying the manifest: %v", tags)
+	}
+}
+
+func TestApplyManifestWithoutRefs(t *testing.T) {
+	left := fakeImageCLI(t, "app:v1", "app:v2")
+
+	// A manifest written before refs were recorded says nothing about tags
+	plan := `{"dryRun": true, "deleted": [{"id": "sha256:aaa", "type": "image", "name": "app:v1"}]}`
+	resources, err := ReadManifest(strings.NewReader(plan))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deleted, errs := DeleteResources(context.Background(), resources)
+	if deleted != 0 || len(errs) != 1 || !errors.Is(errs[0], errTagsKept) {
+		t.Fatalf("DeleteResources() = %d, %v; want the image reported as kept", deleted, errs)
+	}
+	if tags := left(); len(tags) != 2 {
+		t.Errorf("tags left = %v, want both", tags)
+	}
+}
@@ -1,6 +1,9 @@
 package sweep
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/midnattsol/docker-sweep/internal/config"
@@ -16,25 +19,40 @@ type NetworkResource struct {
 	createdAt      time.Time
 	composeProject string
 	protectReason  string
+	orphaned       bool // compose project has no containers left
+	subnet         string
+	gateway        string
 }
 
 // Implement Resource interface
-func (n *NetworkResource) ID() string             { return n.network.ID }
-func (n *NetworkResource) Type() ResourceType     { return TypeNetwork }
-func (n *NetworkResource) Category() Category     { return n.category }
-func (n *NetworkResource) Size() int64            { return 0 }
-func (n *NetworkResource) IsProtected() bool      { return n.category == CategoryProtected }
-func (n *NetworkResource) IsSuggested() bool      { return n.category == CategorySuggested }
-func (n *NetworkResource) CreatedAt() time.Time   { return n.createdAt }
-func (n *NetworkResource) ProtectReason() string  { return n.protectReason }
-func (n *NetworkResource) ComposeProject() string { return n.composeProject }
+func (n *NetworkResource) ID() string                { return n.network.ID }
+func (n *NetworkResource) Type() ResourceType        { return TypeNetwork }
+func (n *NetworkResource) Category() Category        { return n.category }
+func (n *NetworkResource) Size() int64               { return 0 }
+func (n *NetworkResource) IsProtected() bool         { return n.category == CategoryProtected }
+func (n *NetworkResource) IsSuggested() bool         { return n.category == CategorySuggested }
+func (n *NetworkResource) CreatedAt() time.Time      { return n.createdAt }
+func (n *NetworkResource) ProtectReason() string     { return n.protectReason }
+func (n *NetworkResource) Labels() map[string]string { return n.labels }
+func (n *NetworkResource) ComposeProject() string    { return n.composeProject }
+
+// InspectSummary returns the network fields shown in the detail view
+func (n *NetworkResource) InspectSummary() []DetailField {
+	fields := []DetailField{
+		{Name: "Driver", Value: n.network.Driver},
+		{Name: "Scope", Value: n.network.Scope},
+	}
+	if n.subnet != "" {
+		fields = append(fields, DetailField{Name: "Subnet", Value: n.subnet})
+	}
+	if n.gateway != "" {
+		fields = append(fields, DetailField{Name: "Gateway", Value: n.gateway})
+	}
+	return fields
+}
 
 func (n *NetworkResource) DisplayName() string {
-	name := n.network.Name
-	if len(name) > 30 {
-		name = name[:27] + "..."
-	}
-	return name
+	return truncateName(n.network.Name, 30)
 }
 
 func (n *NetworkResource) Details() string {
@@ -44,6 +62,12 @@ func (n *NetworkResource) Details() string {
 	if n.inUse {
 		return "in use"
 	}
+	if n.orphaned {
+		return fmt.Sprintf("orphaned (project %s)", n.composeProject)
+	}
+	if n.subnet != "" {
+		return n.network.Driver + " " + n.subnet
+	}
 	return n.network.Driver
 }
 
@@ -54,22 +78,43 @@ func (n *NetworkResource) Driver() string {
 
 // AnalyzeNetworks lists and categorizes all networks
 func AnalyzeNetworks() ([]NetworkResource, error) {
-	return AnalyzeNetworksWithConfig(config.DefaultConfig())
+	return AnalyzeNetworksWithConfig(config.DefaultConfig(), nil, nil)
 }
 
-// AnalyzeNetworksWithConfig lists and categorizes networks with config options
-func AnalyzeNetworksWithConfig(cfg *config.Config) ([]NetworkResource, error) {
-	networks, err := docker.ListNetworks()
-	if err != nil {
+// AnalyzeNetworksWithConfig is AnalyzeNetworksContext without cancellation,
+// kept for existing callers
+func AnalyzeNetworksWithConfig(cfg *config.Config, filtered *Filtered, cache *docker.ContainerCache) ([]NetworkResource, error) {
+	return AnalyzeNetworksContext(context.Background(), cfg, filtered, cache)
+}
+
+// AnalyzeNetworksContext lists and categorizes networks with config options.
+// Resources excluded by filters are tallied in filtered, which may be nil;
+// cache shares container inspect output with the other analyses of the run
+// and may be nil too.
+// Cancelling ctx kills the runtime commands in flight and fails the analysis.
+func AnalyzeNetworksContext(ctx context.Context, cfg *config.Config, filtered *Filtered, cache *docker.ContainerCache) ([]NetworkResource, error) {
+	networks, err := docker.ListNetworks(ctx)
+	if err != nil && !docker.IsPartialJSON(err) {
 		return nil, err
 	}
 
-	inUse, err := docker.GetNetworksInUse()
+	inUse, err := cache.NetworksInUse(ctx)
+	if errors.Is(err, docker.ErrTimeout) {
+		return nil, err
+	}
 	if err != nil {
 		// Non-fatal, continue without in-use info
 		inUse = make(map[string]bool)
 	}
 
+	var activeProjects map[string]bool
+	if cfg.ProtectActiveProjects {
+		if activeProjects, err = cache.ActiveComposeProjects(ctx); err != nil {
+			return nil, fmt.Errorf("failed to find running Compose projects: %w", err)
+		}
+	}
+
+	olderThan := cfg.OlderThanFor(string(TypeNetwork))
 	var results []NetworkResource
 	for _, net := range networks {
 		used := inUse[net.Name]
@@ -77,23 +122,50 @@ func AnalyzeNetworksWithConfig(cfg *config.Config) ([]NetworkResource, error) {
 		// Get detailed info
 		var labels map[string]string
 		var createdAt time.Time
-		var composeProject string
-		if inspect, err := docker.InspectNetwork(net.ID); err == nil {
+		var composeProject, subnet, gateway string
+		if inspect, err := docker.InspectNetwork(ctx, net.ID); err == nil {
 			labels = inspect.Labels
+			subnet, gateway = inspect.Subnet, inspect.Gateway
 			if t, err := time.Parse(time.RFC3339Nano, inspect.Created); err == nil {
 				createdAt = t
 			}
 			composeProject = docker.ComposeProjectFromLabels(labels)
+		} else if errors.Is(err, docker.ErrTimeout) {
+			return nil, err
 		}
 
 		// Apply filters
-		if cfg.OlderThan > 0 && !createdAt.IsZero() {
-			if time.Since(createdAt) < cfg.OlderThan {
+		if olderThan > 0 && !createdAt.IsZero() {
+			if time.Since(createdAt) < olderThan {
+				filtered.Add(TypeNetwork, "--older-than")
 				continue // Skip: not old enough
 			}
 		}
 
-		category, protectReason := categorizeNetwork(net, used, labels, cfg)
+		if cfg.NewerThan > 0 && !createdAt.IsZero() {
+			if time.Since(createdAt) > cfg.NewerThan {
+				filtered.Add(TypeNetwork, "--newer-than")
+				continue // Skip: too old
+			}
+		}
+
+		if cfg.IsAfterUntil(createdAt) {
+			filtered.Add(TypeNetwork, "--until")
+			continue // Skip: created after the cutoff
+		}
+
+		if !cfg.MatchName(net.Name) {
+			filtered.Add(TypeNetwork, "--name")
+			continue // Skip: name does not match
+		}
+
+		if !cfg.MatchLabels(labels) {
+			filtered.Add(TypeNetwork, "--label")
+			continue // Skip: missing labels
+		}
+
+		category, protectReason := categorizeNetwork(net, used, labels, createdAt, activeProjects, cfg)
+		category = promoteUnused(category, cfg)
 
 		results = append(results, NetworkResource{
 			network:        net,
@@ -103,18 +175,36 @@ func AnalyzeNetworksWithConfig(cfg *config.Config) ([]NetworkResource, error) {
 			createdAt:      createdAt,
 			composeProject: composeProject,
 			protectReason:  protectReason,
+			subnet:         subnet,
+			gateway:        gateway,
 		})
 	}
 
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	return results, nil
 }
 
-func categorizeNetwork(net docker.Network, inUse bool, labels map[string]string, cfg *config.Config) (Category, string) {
+func categorizeNetwork(net docker.Network, inUse bool, labels map[string]string, createdAt time.Time, activeProjects map[string]bool, cfg *config.Config) (Category, string) {
 	// Check protection label
-	if labels != nil && labels[docker.LabelProtect] == "true" {
+	if cfg.IsProtectedByLabel(labels) {
 		return CategoryProtected, "protected by label"
 	}
 
+	if cfg.IsTooRecent(createdAt) {
+		return CategoryProtected, "too recent"
+	}
+
+	if cfg.InGracePeriod(docker.ComposeProjectFromLabels(labels), createdAt) {
+		return CategoryProtected, "compose grace period"
+	}
+
+	if cfg.IsExcluded(net.Name) {
+		return CategoryProtected, "excluded by pattern"
+	}
+
 	// System networks are always protected
 	if docker.SystemNetworks[net.Name] {
 		return CategoryProtected, "system network"
@@ -124,6 +214,10 @@ func categorizeNetwork(net docker.Network, inUse bool, labels map[string]string,
 		return CategoryProtected, "in use by container"
 	}
 
+	if isActiveProject(labels, activeProjects) {
+		return CategoryProtected, "compose project running"
+	}
+
 	// Unused custom networks are suggested
 	return CategorySuggested, ""
 }
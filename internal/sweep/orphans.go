@@ -0,0 +1,46 @@
+package sweep
+
+import (
+	"context"
+
+	"github.com/midnattsol/docker-sweep/internal/docker"
+)
+
+// MarkOrphans flags volumes and networks whose Compose project no longer has
+// any container (running or stopped) and suggests them for deletion.
+// Protected resources keep their category.
+func MarkOrphans(r *Result) error {
+	return MarkOrphansContext(context.Background(), r)
+}
+
+// MarkOrphansContext is MarkOrphans with a context that cancels the lookup
+func MarkOrphansContext(ctx context.Context, r *Result) error {
+	if len(r.Volumes) == 0 && len(r.Networks) == 0 {
+		return nil
+	}
+
+	projects, err := docker.GetComposeProjectsInUse(ctx)
+	if err != nil {
+		return err
+	}
+
+	for i := range r.Volumes {
+		v := &r.Volumes[i]
+		if v.composeProject == "" || projects[v.composeProject] || v.category == CategoryProtected {
+			continue
+		}
+		v.orphaned = true
+		v.category = CategorySuggested
+	}
+
+	for i := range r.Networks {
+		n := &r.Networks[i]
+		if n.composeProject == "" || projects[n.composeProject] || n.category == CategoryProtected {
+			continue
+		}
+		n.orphaned = true
+		n.category = CategorySuggested
+	}
+
+	return nil
+}
@@ -0,0 +1,159 @@
+package sweep
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Timestamp is a time that serializes as RFC3339, or null when unknown
+type Timestamp time.Time
+
+// MarshalJSON implements json.Marshaler
+func (t Timestamp) MarshalJSON() ([]byte, error) {
+	tt := time.Time(t)
+	if tt.IsZero() {
+		return []byte("null"), nil
+	}
+	return json.Marshal(tt.UTC().Format(time.RFC3339))
+}
+
+// UnmarshalJSON implements json.Unmarshaler
+func (t *Timestamp) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*t = Timestamp{}
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	parsed, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return err
+	}
+	*t = Timestamp(parsed)
+	return nil
+}
+
+// Record is the serializable form of a Resource
+type Record struct {
+	ID             string       `json:"id"`
+	Type           ResourceType `json:"type"`
+	Name           string       `json:"name"`
+	Category       Category     `json:"category"`
+	Size           int64        `json:"size"`
+	CreatedAt      Timestamp    `json:"createdAt"`
+	ComposeProject string       `json:"composeProject,omitempty"`
+	ProtectReason  string       `json:"protectReason,omitempty"`
+	Refs           []string     `json:"refs,omitempty"` // chosen repository:tag references of an image
+}
+
+// NewRecord builds a Record from a Resource
+func NewRecord(r Resource) Record {
+	return Record{
+		ID:             r.ID(),
+		Type:           r.Type(),
+		Name:           r.DisplayName(),
+		Category:       r.Category(),
+		Size:           r.Size(),
+		CreatedAt:      Timestamp(GetCreatedAt(r)),
+		ComposeProject: GetComposeProject(r),
+		ProtectReason:  GetProtectReason(r),
+		Refs:           getTagRefs(r),
+	}
+}
+
+// ErrorRecord is the serializable form of a failed deletion
+type ErrorRecord struct {
+	ID      string       `json:"id"`
+	Type    ResourceType `json:"type"`
+	Name    string       `json:"name"`
+	Stage   string       `json:"stage,omitempty"` // "stop" or "remove"
+	Message string       `json:"message"`
+}
+
+// Summary counts the outcome of a deletion run
+type Summary struct {
+	Requested int `json:"requested"`
+	Deleted   int `json:"deleted"`
+	Failed    int `json:"failed"`
+}
+
+// Report is the serializable outcome of a deletion run
+type Report struct {
+	DryRun  bool          `json:"dryRun"`
+	Deleted []Record      `json:"deleted"`
+	Errors  []ErrorRecord `json:"errors"`
+	Summary Summary       `json:"summary"`
+
+	// Protected lists the resources kept by protection (--show-protected)
+	Protected []Record `json:"protected,omitempty"`
+}
+
+// NewRecords converts resources to Records
+func NewRecords(resources []Resource) []Record {
+	records := make([]Record, 0, len(resources))
+	for _, r := range resources {
+		records = append(records, NewRecord(r))
+	}
+	return records
+}
+
+// NewDryRunReport builds a Report listing what a deletion run would remove
+func NewDryRunReport(resources []Resource) Report {
+	return Report{
+		DryRun:  true,
+		Deleted: NewRecords(resources),
+		Errors:  []ErrorRecord{},
+		Summary: Summary{Requested: len(resources), Deleted: len(resources)},
+	}
+}
+
+// NewReport builds a Report from the requested resources and the errors
+// returned by DeleteResources
+func NewReport(requested []Resource, errs []error) Report {
+	// An image selected under several tags is removed once
+	requested = Dedupe(requested)
+	report := Report{
+		Deleted: []Record{},
+		Errors:  []ErrorRecord{},
+	}
+
+	failed := make(map[string]bool)
+	for _, err := range errs {
+		var de *DeleteError
+		if errors.As(err, &de) {
+			failed[resourceKey(de.Resource)] = true
+			stage := "remove"
+			var se *StopError
+			if errors.As(de.Err, &se) {
+				stage = "stop"
+			}
+			report.Errors = append(report.Errors, ErrorRecord{
+				ID:      de.Resource.ID(),
+				Type:    de.Resource.Type(),
+				Name:    de.Resource.DisplayName(),
+				Stage:   stage,
+				Message: de.Err.Error(),
+			})
+			continue
+		}
+		report.Errors = append(report.Errors, ErrorRecord{Message: err.Error()})
+	}
+
+	for _, r := range requested {
+		if !failed[resourceKey(r)] {
+			report.Deleted = append(report.Deleted, NewRecord(r))
+		}
+	}
+
+	report.Summary = Summary{
+		Requested: len(requested),
+		Deleted:   len(report.Deleted),
+		Failed:    len(report.Errors),
+	}
+
+	return report
+}
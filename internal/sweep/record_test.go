@@ -0,0 +1,37 @@
+package sweep
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/midnattsol/docker-sweep/internal/docker"
+)
+
+func TestNewReportCountsImagesOnce(t *testing.T) {
+	web := &ContainerResource{container: docker.Container{ID: "c1", Names: "web"}}
+	appV1 := &ImageResource{image: docker.Image{ID: "sha256:aaa", Repository: "app", Tag: "v1"}}
+	appV2 := &ImageResource{image: docker.Image{ID: "sha256:aaa", Repository: "app", Tag: "v2"}}
+	other := &ImageResource{image: docker.Image{ID: "sha256:bbb", Repository: "other", Tag: "v1"}}
+	localData := &VolumeResource{volume: docker.Volume{Name: "data", Driver: "local"}}
+	nfsData := &VolumeResource{volume: docker.Volume{Name: "data", Driver: "nfs"}}
+
+	errs := []error{
+		&DeleteError{Resource: other, Err: errors.New("conflict")},
+		&DeleteError{Resource: nfsData, Err: errors.New("plugin not found")},
+	}
+	report := NewReport([]Resource{web, appV1, appV2, other, localData, nfsData}, errs)
+
+	want := Summary{Requested: 5, Deleted: 3, Failed: 2}
+	if report.Summary != want {
+		t.Errorf("Summary = %+v, want %+v", report.Summary, want)
+	}
+	volumes := 0
+	for _, r := range report.Deleted {
+		if r.Type == TypeVolume {
+			volumes++
+		}
+	}
+	if volumes != 1 {
+		t.Errorf("reported %d deleted volumes, want only the one on local", volumes)
+	}
+}
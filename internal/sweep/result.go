@@ -1,9 +1,17 @@
 package sweep
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/mattn/go-runewidth"
+
+	"github.com/midnattsol/docker-sweep/internal/config"
 	"github.com/midnattsol/docker-sweep/internal/docker"
 )
 
@@ -27,6 +35,14 @@ const (
 	CategoryUnused    Category = "unused"    // Not in use but not suggested (has custom name/tag)
 )
 
+// promoteUnused suggests unused resources when cfg.All is set
+func promoteUnused(category Category, cfg *config.Config) Category {
+	if cfg.All && category == CategoryUnused {
+		return CategorySuggested
+	}
+	return category
+}
+
 // Resource is the interface for all Docker resources
 type Resource interface {
 	ID() string
@@ -53,12 +69,98 @@ func GetComposeProject(r Resource) string {
 	return ""
 }
 
+// TimedResource is an optional interface for resources with a known creation time
+type TimedResource interface {
+	Resource
+	CreatedAt() time.Time
+}
+
+// GetCreatedAt returns the creation time if the resource implements TimedResource
+func GetCreatedAt(r Resource) time.Time {
+	if tr, ok := r.(TimedResource); ok {
+		return tr.CreatedAt()
+	}
+	return time.Time{}
+}
+
+// ProtectedResource is an optional interface for resources that explain their protection
+type ProtectedResource interface {
+	Resource
+	ProtectReason() string
+}
+
+// GetProtectReason returns the protection reason if the resource implements ProtectedResource
+func GetProtectReason(r Resource) string {
+	if pr, ok := r.(ProtectedResource); ok {
+		return pr.ProtectReason()
+	}
+	return ""
+}
+
+// RiskyResource is an optional interface for resources whose deletion may lose
+// data, such as named volumes
+type RiskyResource interface {
+	Resource
+	Risk() string
+}
+
+// GetRisk returns why deleting the resource is risky, or "" when it is not
+func GetRisk(r Resource) string {
+	if rr, ok := r.(RiskyResource); ok {
+		return rr.Risk()
+	}
+	return ""
+}
+
+// LabeledResource is an optional interface for resources with labels
+type LabeledResource interface {
+	Resource
+	Labels() map[string]string
+}
+
+// GetLabels returns the labels if the resource implements LabeledResource
+func GetLabels(r Resource) map[string]string {
+	if lr, ok := r.(LabeledResource); ok {
+		return lr.Labels()
+	}
+	return nil
+}
+
+// DetailField is one named value in a resource's detail view
+type DetailField struct {
+	Name  string
+	Value string
+}
+
+// DetailedResource is an optional interface for resources with type-specific detail
+type DetailedResource interface {
+	Resource
+	InspectSummary() []DetailField
+}
+
+// GetInspectSummary returns the type-specific detail if the resource implements DetailedResource
+func GetInspectSummary(r Resource) []DetailField {
+	if dr, ok := r.(DetailedResource); ok {
+		return dr.InspectSummary()
+	}
+	return nil
+}
+
 // Result holds all analyzed resources
 type Result struct {
 	Containers []ContainerResource
 	Images     []ImageResource
 	Volumes    []VolumeResource
 	Networks   []NetworkResource
+
+	// Filtered tallies resources excluded by filters during analysis
+	Filtered *Filtered
+
+	// Sort orders Suggested, Resources, Protected and All by a sort key;
+	// empty keeps the analysis order
+	Sort string
+
+	layers *layerIndex // set by IndexLayers
 }
 
 // IsEmpty returns true if there are no resources to show
@@ -71,6 +173,14 @@ func (r *Result) IsEmpty() bool {
 
 // Suggested returns all resources suggested for deletion
 func (r *Result) Suggested() []Resource {
+	suggested := Dedupe(r.suggestedUnsorted())
+	SortResources(suggested, r.Sort)
+	return suggested
+}
+
+// suggestedUnsorted returns the suggested resources in analysis order,
+// repeats included
+func (r *Result) suggestedUnsorted() []Resource {
 	var suggested []Resource
 
 	for i := range r.Containers {
@@ -93,10 +203,136 @@ func (r *Result) Suggested() []Resource {
 			suggested = append(suggested, &r.Networks[i])
 		}
 	}
-
 	return suggested
 }
 
+// Resources returns every analyzed resource, including protected ones
+func (r *Result) Resources() []Resource {
+	var all []Resource
+
+	for i := range r.Containers {
+		all = append(all, &r.Containers[i])
+	}
+	for i := range r.Images {
+		all = append(all, &r.Images[i])
+	}
+	for i := range r.Volumes {
+		all = append(all, &r.Volumes[i])
+	}
+	for i := range r.Networks {
+		all = append(all, &r.Networks[i])
+	}
+
+	SortResources(all, r.Sort)
+	return all
+}
+
+// resourceKey identifies a resource by type and ID. Volume names are only
+// unique per driver, so volumes also include their driver.
+func resourceKey(r Resource) string {
+	key := string(r.Type()) + "/" + r.ID()
+	if v, ok := r.(*VolumeResource); ok {
+		key += "/" + v.volume.Driver
+	}
+	return key
+}
+
+// taggedResource is implemented by image resources that know which of the
+// image's repository:tag references were chosen
+type taggedResource interface {
+	Resource
+	tagRefs() []string
+}
+
+// getTagRefs returns the chosen repository:tag references of an image
+// resource, or nil
+func getTagRefs(r Resource) []string {
+	if tr, ok := r.(taggedResource); ok && r.Type() == TypeImage {
+		return tr.tagRefs()
+	}
+	return nil
+}
+
+// RowKeys returns a key for each listed row r stands for: its type and ID
+// (and driver, for volumes), plus the repository:tag for images, so that the
+// tags of one image are told apart. A deduplicated image has a key for each
+// tag it was selected under.
+func RowKeys(r Resource) []string {
+	key := resourceKey(r)
+	if r.Type() != TypeImage {
+		return []string{key}
+	}
+	refs := getTagRefs(r)
+	if len(refs) == 0 {
+		return []string{key + "/"}
+	}
+	keys := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		keys = append(keys, key+"/"+ref)
+	}
+	return keys
+}
+
+// Dedupe removes repeated resources (same resourceKey), keeping the first
+// occurrence. An image listed under several tags is kept as a copy of its
+// first row that also carries the repository:tag of the others, so removing
+// it drops every tag that was chosen.
+func Dedupe(resources []Resource) []Resource {
+	index := make(map[string]int, len(resources))
+	var unique []Resource
+	for _, r := range resources {
+		key := resourceKey(r)
+		if i, seen := index[key]; seen {
+			unique[i] = mergeTags(unique[i], r)
+			continue
+		}
+		index[key] = len(unique)
+		unique = append(unique, r)
+	}
+	return unique
+}
+
+// mergeTags returns first with the repository:tag references of dup, another
+// row of the same image, added. first itself is left untouched.
+func mergeTags(first, dup Resource) Resource {
+	img, ok := first.(*ImageResource)
+	other, ok2 := dup.(*ImageResource)
+	if !ok || !ok2 {
+		return first
+	}
+
+	refs := slices.Clone(img.tagRefs())
+	for _, ref := range other.tagRefs() {
+		if !slices.Contains(refs, ref) {
+			refs = append(refs, ref)
+		}
+	}
+	if len(refs) == len(img.tagRefs()) {
+		return first
+	}
+	merged := *img
+	merged.mergedRefs = refs
+	return &merged
+}
+
+// truncateName shortens s to at most width terminal cells, ending it with
+// "..." when cut. Wide CJK runes and emoji count double, and grapheme
+// clusters (a letter and its combining marks, emoji sequences) are never split.
+func truncateName(s string, width int) string {
+	return runewidth.Truncate(s, width, "...")
+}
+
+// Protected returns all protected resources
+func (r *Result) Protected() []Resource {
+	var protected []Resource
+	for _, res := range r.Resources() {
+		if res.IsProtected() {
+			protected = append(protected, res)
+		}
+	}
+	return protected
+}
+
 // All returns all non-protected resources
 func (r *Result) All() []Resource {
 	var all []Resource
@@ -122,6 +358,7 @@ func (r *Result) All() []Resource {
 		}
 	}
 
+	SortResources(all, r.Sort)
 	return all
 }
 
@@ -134,11 +371,91 @@ func (r *Result) TotalSize() int64 {
 	return total
 }
 
+// DeleteError describes a failed deletion of a single resource
+type DeleteError struct {
+	Resource Resource
+	Err      error
+}
+
+func (e *DeleteError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Resource.DisplayName(), e.Err)
+}
+
+func (e *DeleteError) Unwrap() error {
+	return e.Err
+}
+
+// StopError reports a container that could not be stopped before removal
+type StopError struct {
+	Err error
+}
+
+func (e *StopError) Error() string {
+	return fmt.Sprintf("failed to stop: %v", e.Err)
+}
+
+func (e *StopError) Unwrap() error {
+	return e.Err
+}
+
+// errHasDependents is reported for images still referenced after all retries
+var errHasDependents = errors.New("has dependent images (not deleted)")
+
+// errTagsKept is reported for images whose selected tags were removed while
+// tags that were not selected still keep the image
+var errTagsKept = errors.New("selected tags removed, image kept")
+
+// ErrInterrupted is reported for resources skipped because the deletion
+// context was cancelled before their removal started
+var ErrInterrupted = errors.New("interrupted before deletion")
+
+// ProgressFunc is called after each resource deletion attempt is settled
+type ProgressFunc func(done, total int)
+
+// progress tracks settled deletions and reports them to a ProgressFunc.
+// It is safe for concurrent use.
+type progress struct {
+	mu    sync.Mutex
+	done  int
+	total int
+	fn    ProgressFunc
+}
+
+func (p *progress) step() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done++
+	if p.fn != nil {
+		p.fn(p.done, p.total)
+	}
+}
+
 // DeleteResources deletes the given resources in the correct order:
 // 1. Containers first (so images/volumes/networks can be freed)
 // 2. Networks and Volumes (order doesn't matter between them)
 // 3. Images last (with retry for dependency resolution)
-func DeleteResources(resources []Resource) (int, []error) {
+//
+// Once ctx is cancelled no new removal starts: those in flight finish, and
+// every resource not yet attempted fails with ErrInterrupted.
+func DeleteResources(ctx context.Context, resources []Resource) (int, []error) {
+	return DeleteResourcesWithProgress(ctx, resources, nil)
+}
+
+// DeleteResourcesWithProgress deletes resources like DeleteResources and
+// reports progress through fn (which may be nil)
+func DeleteResourcesWithProgress(ctx context.Context, resources []Resource, fn ProgressFunc) (int, []error) {
+	return DeleteResourcesWithJobs(ctx, resources, 1, fn)
+}
+
+// DeleteResourcesWithJobs deletes resources like DeleteResourcesWithProgress,
+// removing up to jobs resources of the same phase at once. Phases still run
+// in order, and errors are returned in the order of resources.
+func DeleteResourcesWithJobs(ctx context.Context, resources []Resource, jobs int, fn ProgressFunc) (int, []error) {
+	resources = Dedupe(resources)
+	tags := selectedTags(resources)
+	prog := &progress{total: len(resources), fn: fn}
+	exists := docker.NewExistsCache()
+
 	// Separate by type
 	var containers, images, volumes, networks []Resource
 	for _, r := range resources {
@@ -158,90 +475,262 @@ func DeleteResources(resources []Resource) (int, []error) {
 	var allErrors []error
 
 	// 1. Containers first
-	d, e := deleteAll(containers)
+	d, e := deleteAll(ctx, containers, jobs, prog, exists)
 	totalDeleted += d
 	allErrors = append(allErrors, e...)
 
 	// 2. Networks
-	d, e = deleteAll(networks)
+	d, e = deleteAll(ctx, networks, jobs, prog, exists)
 	totalDeleted += d
 	allErrors = append(allErrors, e...)
 
 	// 3. Volumes
-	d, e = deleteAll(volumes)
+	d, e = deleteAll(ctx, volumes, jobs, prog, exists)
 	totalDeleted += d
 	allErrors = append(allErrors, e...)
 
 	// 4. Images last, with retry for dependencies
-	d, e = deleteImagesWithRetry(images)
+	d, e = deleteImagesWithRetry(ctx, images, tags, jobs, prog, exists)
 	totalDeleted += d
 	allErrors = append(allErrors, e...)
 
 	return totalDeleted, allErrors
 }
 
+// parallel calls fn for every resource in order, running up to jobs calls at
+// once. It stops dispatching when ctx is cancelled, waits for the calls in
+// flight and returns how many resources were dispatched.
+func parallel(ctx context.Context, resources []Resource, jobs int, fn func(i int, r Resource)) int {
+	workers := min(max(jobs, 1), len(resources))
+	work := make(chan int)
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				fn(i, resources[i])
+			}
+		}()
+	}
+
+	dispatched := len(resources)
+dispatch:
+	for i := range resources {
+		if ctx.Err() != nil {
+			dispatched = i
+			break
+		}
+		select {
+		case work <- i:
+		case <-ctx.Done():
+			dispatched = i
+			break dispatch
+		}
+	}
+	close(work)
+	wg.Wait()
+	return dispatched
+}
+
+// interrupted fails resources that were never dispatched with ErrInterrupted
+func interrupted(resources []Resource, prog *progress) []error {
+	errors := make([]error, 0, len(resources))
+	for _, r := range resources {
+		errors = append(errors, &DeleteError{Resource: r, Err: ErrInterrupted})
+		prog.step()
+	}
+	return errors
+}
+
 // deleteAll deletes resources without retry
-func deleteAll(resources []Resource) (int, []error) {
+func deleteAll(ctx context.Context, resources []Resource, jobs int, prog *progress, exists existsChecker) (int, []error) {
+	// Each worker only writes its own index, so no locking is needed
+	results := make([]error, len(resources))
+	n := parallel(ctx, resources, jobs, func(i int, res Resource) {
+		if err := remove(res); err != nil && !isAlreadyRemoved(res, err, exists) {
+			results[i] = &DeleteError{Resource: res, Err: err}
+		}
+		prog.step()
+	})
+
 	var deleted int
 	var errors []error
-
-	for _, res := range resources {
-		if err := docker.Remove(string(res.Type()), res.ID()); err != nil {
-			if isAlreadyRemovedError(res.Type(), err) {
-				deleted++
-				continue
-			}
-			errors = append(errors, fmt.Errorf("%s: %w", res.DisplayName(), err))
+	for _, err := range results[:n] {
+		if err != nil {
+			errors = append(errors, err)
 		} else {
 			deleted++
 		}
 	}
 
-	return deleted, errors
+	return deleted, append(errors, interrupted(resources[n:], prog)...)
 }
 
 // deleteImagesWithRetry deletes images with retry for dependency resolution.
 // Images can have parent-child relationships, so we may need multiple passes.
-func deleteImagesWithRetry(resources []Resource) (int, []error) {
+// A localized CLI's dependency errors cannot be recognized, so any failure
+// that leaves the image listed is retried as well until the last pass.
+// tags holds the repository:tag references selected for each image ID.
+func deleteImagesWithRetry(ctx context.Context, resources []Resource, tags map[string]map[string]bool, jobs int, prog *progress, exists existsChecker) (int, []error) {
 	var deleted int
 	var errors []error
 	pending := resources
 
 	// Maximum 3 passes to resolve dependencies
-	for attempt := 0; attempt < 3 && len(pending) > 0; attempt++ {
-		var failed []Resource
-		for _, r := range pending {
-			if err := docker.Remove(string(r.Type()), r.ID()); err != nil {
-				if isAlreadyRemovedError(r.Type(), err) {
-					deleted++
-					continue
-				}
+	const passes = 3
+	for attempt := 0; attempt < passes && len(pending) > 0 && ctx.Err() == nil; attempt++ {
+		results := make([]error, len(pending))
+		retry := make([]bool, len(pending))
+		last := attempt == passes-1
+		n := parallel(ctx, pending, jobs, func(i int, r Resource) {
+			err := remove(r)
+			// Several tags point at the image: drop them one by one instead
+			if isMultipleReferencesError(err) {
+				err = untagAndRemove(ctx, r, tags[r.ID()])
+			}
+			switch {
+			case err == nil:
+			case isTagsKept(err):
+				results[i] = &DeleteError{Resource: r, Err: err}
+			case isDependencyError(err):
 				// If it's a dependency error, retry later
-				if isDependencyError(err) {
-					failed = append(failed, r)
-				} else {
-					errors = append(errors, fmt.Errorf("%s: %w", r.DisplayName(), err))
-				}
-			} else {
+				retry[i] = true
+				return
+			case isAlreadyRemoved(r, err, exists):
+			case !last:
+				// The image is still listed and the error unrecognized, as
+				// with a localized CLI: it may be a dependency, so retry
+				retry[i] = true
+				return
+			default:
+				results[i] = &DeleteError{Resource: r, Err: err}
+			}
+			prog.step()
+		})
+
+		var failed []Resource
+		for i, r := range pending {
+			switch {
+			case i >= n, retry[i]:
+				failed = append(failed, r)
+			case results[i] != nil:
+				errors = append(errors, results[i])
+			default:
 				deleted++
 			}
 		}
 		pending = failed
 	}
 
+	if ctx.Err() != nil {
+		return deleted, append(errors, interrupted(pending, prog)...)
+	}
+
 	// What's left after 3 attempts has unresolvable dependencies
 	for _, r := range pending {
-		errors = append(errors, fmt.Errorf("%s: has dependent images (not deleted)", r.DisplayName()))
+		errors = append(errors, &DeleteError{Resource: r, Err: errHasDependents})
+		prog.step()
 	}
 
 	return deleted, errors
 }
 
+// remove deletes one resource. Containers suggested while active are stopped
+// first (--stop) or removed with rm -f (--force); untagged images may be
+// removed by digest.
+func remove(res Resource) error {
+	if c, ok := res.(*ContainerResource); ok {
+		if c.stopFirst {
+			if err := docker.Stop(c.ID(), c.stopTimeout); err != nil {
+				return &StopError{Err: err}
+			}
+		}
+		if c.force {
+			return docker.RemoveForce(string(res.Type()), res.ID())
+		}
+	}
+	if img, ok := res.(*ImageResource); ok {
+		return docker.RemoveImage(img.removeRefs()...)
+	}
+	return docker.Remove(string(res.Type()), res.ID())
+}
+
+// isTagsKept reports whether err is a partial removal by untagAndRemove
+func isTagsKept(err error) bool {
+	return errors.Is(err, errTagsKept)
+}
+
+// isMultipleReferencesError checks if removing an image by ID failed because
+// more than one repository:tag references it
+func isMultipleReferencesError(err error) bool {
+	if err == nil {
+		return false
+	}
+	errStr := strings.ToLower(err.Error())
+	return strings.Contains(errStr, "referenced in multiple repositories") || // docker
+		strings.Contains(errStr, "more than one tag") // podman
+}
+
+// selectedTags maps each image ID to the repository:tag references chosen
+// for it, which Dedupe gathered from all of its rows (or a manifest recorded)
+func selectedTags(resources []Resource) map[string]map[string]bool {
+	tags := make(map[string]map[string]bool)
+	for _, r := range resources {
+		for _, ref := range getTagRefs(r) {
+			if tags[r.ID()] == nil {
+				tags[r.ID()] = make(map[string]bool)
+			}
+			tags[r.ID()][ref] = true
+		}
+	}
+	return tags
+}
+
+// untagAndRemove removes the selected repository:tag references of an image,
+// which deletes it with the last one, then removes what is left by ID. Tags
+// that were not selected (protected, excluded or out of scope) are kept, and
+// so is the image while any of them remains: that is reported as
+// errTagsKept rather than as a deletion. References that are already gone do
+// not count as failures.
+func untagAndRemove(ctx context.Context, res Resource, selected map[string]bool) error {
+	inspect, err := docker.InspectImage(ctx, res.ID())
+	if err != nil {
+		return err
+	}
+
+	var kept []string
+	for _, tag := range inspect.RepoTags {
+		if !selected[tag] {
+			kept = append(kept, tag)
+			continue
+		}
+		if err := docker.RemoveImage(tag); err != nil && !isAlreadyRemovedError(TypeImage, err) {
+			return err
+		}
+	}
+	if len(kept) > 0 {
+		return fmt.Errorf("%w by %s", errTagsKept, strings.Join(kept, ", "))
+	}
+
+	// Untagging may have just deleted the image, so a run's earlier listing
+	// would be stale: list afresh
+	var fresh *docker.ExistsCache
+	if err := docker.RemoveImage(res.ID()); err != nil && !isAlreadyRemoved(res, err, fresh) {
+		return err
+	}
+	return nil
+}
+
 // isDependencyError checks if the error is due to image dependencies
 func isDependencyError(err error) bool {
 	if err == nil {
 		return false
 	}
+	if docker.IsConflict(err) {
+		return true
+	}
 	errStr := strings.ToLower(err.Error())
 	return strings.Contains(errStr, "dependent") ||
 		strings.Contains(errStr, "has dependent child images") ||
@@ -249,12 +738,32 @@ func isDependencyError(err error) bool {
 		strings.Contains(errStr, "image has dependent child images")
 }
 
+// existsChecker tells whether a resource is still present, as
+// *docker.ExistsCache does
+type existsChecker interface {
+	Exists(resourceType, id string) (bool, error)
+}
+
+// isAlreadyRemoved reports whether a failed removal still left the resource
+// gone. The error is checked first; when it is not recognized (e.g. a
+// localized CLI) exists is asked whether the resource is still listed.
+func isAlreadyRemoved(res Resource, err error, exists existsChecker) bool {
+	if isAlreadyRemovedError(res.Type(), err) {
+		return true
+	}
+	present, checkErr := exists.Exists(string(res.Type()), res.ID())
+	return checkErr == nil && !present
+}
+
 // isAlreadyRemovedError checks if the resource is already gone.
 // These errors should be treated as idempotent success.
 func isAlreadyRemovedError(resourceType ResourceType, err error) bool {
 	if err == nil {
 		return false
 	}
+	if docker.IsNotFound(err) {
+		return true
+	}
 	errStr := strings.ToLower(err.Error())
 
 	common := strings.Contains(errStr, "not found") || strings.Contains(errStr, "no such")
@@ -277,3 +786,66 @@ func isAlreadyRemovedError(resourceType ResourceType, err error) bool {
 
 	return false
 }
+
+// CategoryUsage counts the resources of one category and their size
+type CategoryUsage struct {
+	Count int   `json:"count"`
+	Size  int64 `json:"size"`
+}
+
+func (u *CategoryUsage) add(r Resource) {
+	u.Count++
+	u.Size += r.Size()
+}
+
+// TypeUsage summarizes the analyzed resources of one type by category.
+// InUse counts the kept resources that are running, mounted or referenced by
+// a container; Protected the ones kept for any other reason.
+type TypeUsage struct {
+	Type      ResourceType  `json:"type"`
+	Suggested CategoryUsage `json:"suggested"`
+	Unused    CategoryUsage `json:"unused"`
+	InUse     CategoryUsage `json:"inUse"`
+	Protected CategoryUsage `json:"protected"`
+}
+
+// inUse reports whether a container is active, or an image, volume or
+// network is used by a container
+func inUse(res Resource) bool {
+	switch r := res.(type) {
+	case *ContainerResource:
+		return isActiveState(r.container.State)
+	case *ImageResource:
+		return r.inUse
+	case *VolumeResource:
+		return r.inUse
+	case *NetworkResource:
+		return r.inUse
+	}
+	return false
+}
+
+// Usage summarizes the result per type, in deletion display order
+func (r *Result) Usage() []TypeUsage {
+	usage := []TypeUsage{{Type: TypeContainer}, {Type: TypeImage}, {Type: TypeVolume}, {Type: TypeNetwork}}
+	index := map[ResourceType]int{TypeContainer: 0, TypeImage: 1, TypeVolume: 2, TypeNetwork: 3}
+
+	for _, res := range Dedupe(r.Resources()) {
+		u := &usage[index[res.Type()]]
+		switch res.Category() {
+		case CategorySuggested:
+			u.Suggested.add(res)
+		case CategoryUnused:
+			u.Unused.add(res)
+		case CategoryInUse:
+			u.InUse.add(res)
+		default:
+			if inUse(res) {
+				u.InUse.add(res)
+			} else {
+				u.Protected.add(res)
+			}
+		}
+	}
+	return usage
+}
@@ -0,0 +1,127 @@
+package sweep
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/mattn/go-runewidth"
+
+	"github.com/midnattsol/docker-sweep/internal/docker"
+)
+
+func TestTruncateNameMultibyte(t *testing.T) {
+	names := map[string]string{
+		"ascii":     strings.Repeat("backend-service-", 4),
+		"cjk":       strings.Repeat("数据库容器", 10),
+		"emoji":     strings.Repeat("🐳📦", 20),
+		"zwj emoji": strings.Repeat("👩‍💻", 20),
+		"combining": strings.Repeat("cafe\u0301-", 15),
+		"mixed":     "web-前端-🚀-" + strings.Repeat("e\u0308", 30),
+	}
+
+	for name, s := range names {
+		for _, width := range []int{20, 25, 30} {
+			got := truncateName(s, width)
+			if !utf8.ValidString(got) {
+				t.Errorf("%s at %d: %q is not valid UTF-8", name, width, got)
+			}
+			if w := runewidth.StringWidth(got); w > width {
+				t.Errorf("%s at %d: %q is %d cells wide", name, width, got, w)
+			}
+			if !strings.HasSuffix(got, "...") {
+				t.Errorf("%s at %d: %q does not end with ...", name, width, got)
+			}
+		}
+	}
+}
+
+func TestTruncateNameFits(t *testing.T) {
+	for _, s := range []string{"web", "数据库", "🐳 whale", "cafe\u0301"} {
+		if got := truncateName(s, 20); got != s {
+			t.Errorf("truncateName(%q, 20) = %q, want it unchanged", s, got)
+		}
+	}
+}
+
+func TestDedupeOverlappingSelections(t *testing.T) {
+	web := &ContainerResource{container: docker.Container{ID: "c1", Names: "web"}}
+	webAgain := &ContainerResource{container: docker.Container{ID: "c1", Names: "web"}}
+	appV1 := &ImageResource{image: docker.Image{ID: "sha256:aaa", Repository: "app", Tag: "v1"}}
+	appV2 := &ImageResource{image: docker.Image{ID: "sha256:aaa", Repository: "app", Tag: "v2"}}
+	mirror := &ImageResource{image: docker.Image{ID: "sha256:aaa", Repository: "registry.local/app", Tag: "v1"}}
+	other := &ImageResource{image: docker.Image{ID: "sha256:bbb", Repository: "other", Tag: "v1"}}
+	localData := &VolumeResource{volume: docker.Volume{Name: "data", Driver: "local"}}
+	nfsData := &VolumeResource{volume: docker.Volume{Name: "data", Driver: "nfs"}}
+
+	// --name and --label both matched web, and every tag of sha256:aaa was picked
+	got := Dedupe([]Resource{web, appV1, localData, webAgain, appV2, mirror, other, nfsData, localData})
+
+	want := []Resource{web, appV1, localData, other, nfsData}
+	if len(got) != len(want) {
+		t.Fatalf("Dedupe() returned %d resources, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if resourceKey(got[i]) != resourceKey(want[i]) {
+			t.Errorf("Dedupe()[%d] = %s %s, want %s %s", i, got[i].Type(), got[i].DisplayName(), want[i].Type(), want[i].DisplayName())
+		}
+	}
+
+	// The image keeps every tag it was picked under, without touching the rows
+	refs := got[1].(*ImageResource).tagRefs()
+	if want := []string{"app:v1", "app:v2", "registry.local/app:v1"}; !slices.Equal(refs, want) {
+		t.Errorf("merged image refs = %v, want %v", refs, want)
+	}
+	if refs := appV1.tagRefs(); !slices.Equal(refs, []string{"app:v1"}) {
+		t.Errorf("Dedupe changed the first row's refs to %v", refs)
+	}
+}
+
+func TestUsageInUse(t *testing.T) {
+	r := &Result{
+		Containers: []ContainerResource{
+			{container: docker.Container{ID: "c1", State: "running"}, category: CategoryProtected},
+			{container: docker.Container{ID: "c2", State: "exited"}, category: CategorySuggested},
+			{container: docker.Container{ID: "c3", State: "exited"}, category: CategoryProtected},
+		},
+		Images: []ImageResource{
+			{image: docker.Image{ID: "sha256:aaa"}, category: CategoryProtected, inUse: true, size: 100},
+			{image: docker.Image{ID: "sha256:bbb"}, category: CategoryInUse, size: 10},
+		},
+	}
+
+	usage := r.Usage()
+	containers, images := usage[0], usage[1]
+	if containers.InUse.Count != 1 || containers.Protected.Count != 1 || containers.Suggested.Count != 1 {
+		t.Errorf("containers = %+v, want 1 in use, 1 protected, 1 suggested", containers)
+	}
+	if images.InUse != (CategoryUsage{Count: 2, Size: 110}) || images.Protected.Count != 0 {
+		t.Errorf("images = %+v, want 2 in use (110 bytes), none protected", images)
+	}
+}
+
+func TestRowKeys(t *testing.T) {
+	v1 := &ImageResource{image: docker.Image{ID: "sha256:aaa", Repository: "app", Tag: "v1"}}
+	v2 := &ImageResource{image: docker.Image{ID: "sha256:aaa", Repository: "app", Tag: "v2"}}
+	dangling := &ImageResource{image: docker.Image{ID: "sha256:bbb", Repository: "<none>", Tag: "<none>"}}
+	container := &ContainerResource{container: docker.Container{ID: "data"}}
+	volume := &VolumeResource{volume: docker.Volume{Name: "data", Driver: "local"}}
+
+	if k1, k2 := RowKeys(v1), RowKeys(v2); slices.Equal(k1, k2) {
+		t.Errorf("two tags of one image share the key %v", k1)
+	}
+	if k1, k2 := RowKeys(container), RowKeys(volume); slices.Equal(k1, k2) {
+		t.Errorf("a container and a volume named alike share the key %v", k1)
+	}
+	if keys := RowKeys(dangling); len(keys) != 1 {
+		t.Errorf("RowKeys(untagged image) = %v, want one key", keys)
+	}
+
+	// A deduplicated image saves the keys of every row it was selected under
+	merged := RowKeys(Dedupe([]Resource{v1, v2})[0])
+	want := append(RowKeys(v1), RowKeys(v2)...)
+	if !slices.Equal(merged, want) {
+		t.Errorf("RowKeys(merged) = %v, want %v", merged, want)
+	}
+}
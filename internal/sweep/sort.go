@@ -0,0 +1,70 @@
+package sweep
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Sort keys accepted by SortResources
+const (
+	SortName = "name"
+	SortSize = "size"
+	SortAge  = "age"
+	SortType = "type"
+)
+
+// SortKeys lists the keys in the order --sort documents them
+var SortKeys = []string{SortName, SortSize, SortAge, SortType}
+
+// ValidateSortKey returns an error unless key is empty or one of SortKeys
+func ValidateSortKey(key string) error {
+	if key == "" {
+		return nil
+	}
+	for _, k := range SortKeys {
+		if key == k {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid --sort %q: use %s", key, strings.Join(SortKeys, ", "))
+}
+
+// typeOrder is the order types are listed in
+var typeOrder = map[ResourceType]int{TypeContainer: 0, TypeImage: 1, TypeVolume: 2, TypeNetwork: 3}
+
+// lessBy returns the comparator of key: names ascending, the largest and
+// the oldest first (unknown sizes and dates last), or types in listing order
+func lessBy(key string) func(a, b Resource) bool {
+	switch key {
+	case SortName:
+		return func(a, b Resource) bool { return a.DisplayName() < b.DisplayName() }
+	case SortSize:
+		return func(a, b Resource) bool { return a.Size() > b.Size() }
+	case SortAge:
+		return func(a, b Resource) bool {
+			ta, tb := GetCreatedAt(a), GetCreatedAt(b)
+			if ta.IsZero() || tb.IsZero() {
+				return !ta.IsZero() && tb.IsZero()
+			}
+			return ta.Before(tb)
+		}
+	case SortType:
+		return func(a, b Resource) bool { return typeOrder[a.Type()] < typeOrder[b.Type()] }
+	default:
+		return nil
+	}
+}
+
+// SortResources orders resources in place by key. The sort is stable, so
+// ties keep their analysis order; an empty or unknown key changes nothing.
+// Deletion still runs type by type, so sorting only orders each phase.
+func SortResources(resources []Resource, key string) {
+	less := lessBy(key)
+	if less == nil {
+		return
+	}
+	sort.SliceStable(resources, func(i, j int) bool {
+		return less(resources[i], resources[j])
+	})
+}
@@ -1,6 +1,15 @@
 package sweep
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
 	"time"
 
 	"github.com/midnattsol/docker-sweep/internal/config"
@@ -12,36 +21,54 @@ type VolumeResource struct {
 	volume         docker.Volume
 	category       Category
 	inUse          bool
+	size           int64
+	mountpoint     string
 	labels         map[string]string
 	createdAt      time.Time
 	composeProject string
 	protectReason  string
+	anonymous      bool   // by name, or by Podman's inspect flag or label
+	orphaned       bool   // compose project has no containers left
+	risk           string // why deleting it may lose data, empty when it should not
 }
 
 // Implement Resource interface
-func (v *VolumeResource) ID() string             { return v.volume.Name }
-func (v *VolumeResource) Type() ResourceType     { return TypeVolume }
-func (v *VolumeResource) Category() Category     { return v.category }
-func (v *VolumeResource) Size() int64            { return 0 } // Volume size requires filesystem access
-func (v *VolumeResource) IsProtected() bool      { return v.category == CategoryProtected }
-func (v *VolumeResource) IsSuggested() bool      { return v.category == CategorySuggested }
-func (v *VolumeResource) CreatedAt() time.Time   { return v.createdAt }
-func (v *VolumeResource) ProtectReason() string  { return v.protectReason }
-func (v *VolumeResource) ComposeProject() string { return v.composeProject }
+func (v *VolumeResource) ID() string                { return v.volume.Name }
+func (v *VolumeResource) Type() ResourceType        { return TypeVolume }
+func (v *VolumeResource) Category() Category        { return v.category }
+func (v *VolumeResource) Size() int64               { return v.size } // Only measured with --volume-sizes
+func (v *VolumeResource) IsProtected() bool         { return v.category == CategoryProtected }
+func (v *VolumeResource) IsSuggested() bool         { return v.category == CategorySuggested }
+func (v *VolumeResource) CreatedAt() time.Time      { return v.createdAt }
+func (v *VolumeResource) ProtectReason() string     { return v.protectReason }
+func (v *VolumeResource) Labels() map[string]string { return v.labels }
+func (v *VolumeResource) Risk() string              { return v.risk }
+func (v *VolumeResource) ComposeProject() string    { return v.composeProject }
 
-func (v *VolumeResource) DisplayName() string {
-	name := v.volume.Name
-	if len(name) > 30 {
-		name = name[:27] + "..."
+// InspectSummary returns the volume fields shown in the detail view
+func (v *VolumeResource) InspectSummary() []DetailField {
+	fields := []DetailField{{Name: "Driver", Value: v.volume.Driver}}
+	if v.mountpoint != "" {
+		fields = append(fields, DetailField{Name: "Mountpoint", Value: v.mountpoint})
+	}
+	if v.risk != "" {
+		fields = append(fields, DetailField{Name: "Warning", Value: v.risk})
 	}
-	return name
+	return fields
+}
+
+func (v *VolumeResource) DisplayName() string {
+	return truncateName(v.volume.Name, 30)
 }
 
 func (v *VolumeResource) Details() string {
 	if v.inUse {
 		return "in use"
 	}
-	if docker.IsAnonymousVolume(v.volume.Name) {
+	if v.orphaned {
+		return fmt.Sprintf("orphaned (project %s)", v.composeProject)
+	}
+	if v.anonymous {
 		return "anonymous"
 	}
 	return "unused"
@@ -49,102 +76,306 @@ func (v *VolumeResource) Details() string {
 
 // IsAnonymous returns true if this is an anonymous volume
 func (v *VolumeResource) IsAnonymous() bool {
-	return docker.IsAnonymousVolume(v.volume.Name)
+	return v.anonymous
 }
 
 // AnalyzeVolumes lists and categorizes all volumes
 func AnalyzeVolumes() ([]VolumeResource, error) {
-	return AnalyzeVolumesWithConfig(config.DefaultConfig())
+	return AnalyzeVolumesWithConfig(config.DefaultConfig(), nil, nil)
 }
 
-// AnalyzeVolumesWithConfig lists and categorizes volumes with config options
-func AnalyzeVolumesWithConfig(cfg *config.Config) ([]VolumeResource, error) {
-	volumes, err := docker.ListVolumes()
-	if err != nil {
+// AnalyzeVolumesWithConfig is AnalyzeVolumesContext without cancellation,
+// kept for existing callers
+func AnalyzeVolumesWithConfig(cfg *config.Config, filtered *Filtered, cache *docker.ContainerCache) ([]VolumeResource, error) {
+	return AnalyzeVolumesContext(context.Background(), cfg, filtered, cache)
+}
+
+// AnalyzeVolumesContext lists and categorizes volumes with config options.
+// Resources excluded by filters are tallied in filtered, which may be nil;
+// cache shares container inspect output with the other analyses of the run
+// and may be nil too.
+// Cancelling ctx kills the runtime commands in flight and fails the analysis.
+func AnalyzeVolumesContext(ctx context.Context, cfg *config.Config, filtered *Filtered, cache *docker.ContainerCache) ([]VolumeResource, error) {
+	volumes, err := docker.ListVolumes(ctx)
+	if err != nil && !docker.IsPartialJSON(err) {
 		return nil, err
 	}
 
+	// Names are only unique per driver; inspect and rm take the name alone
 	volumeNames := make([]string, 0, len(volumes))
+	drivers := make(map[string]map[string]bool)
 	for _, vol := range volumes {
-		if vol.Name != "" {
+		if vol.Name == "" {
+			continue
+		}
+		if drivers[vol.Name] == nil {
+			drivers[vol.Name] = make(map[string]bool)
 			volumeNames = append(volumeNames, vol.Name)
 		}
+		drivers[vol.Name][vol.Driver] = true
 	}
 
-	inspectByName, err := docker.InspectVolumes(volumeNames)
-	if err != nil {
-		inspectByName = make(map[string]*docker.VolumeInspect)
+	inspectByName := make(map[string]*docker.VolumeInspect)
+	if !cfg.Fast {
+		batchInspect, err := docker.InspectVolumes(ctx, volumeNames)
+		if errors.Is(err, docker.ErrTimeout) {
+			return nil, err
+		}
+		if err == nil {
+			inspectByName = batchInspect
+		}
 	}
 
-	inUse, err := docker.GetVolumesInUse()
+	inUse, err := cache.VolumesInUse(ctx)
+	if errors.Is(err, docker.ErrTimeout) {
+		return nil, err
+	}
 	if err != nil {
 		// Non-fatal, continue without in-use info
-		inUse = make(map[string]bool)
+		inUse = make(docker.VolumeMounts)
 	}
 
+	var activeProjects map[string]bool
+	if cfg.ProtectActiveProjects {
+		if activeProjects, err = cache.ActiveComposeProjects(ctx); err != nil {
+			return nil, fmt.Errorf("failed to find running Compose projects: %w", err)
+		}
+	}
+
+	olderThan := cfg.OlderThanFor(string(TypeVolume))
 	var results []VolumeResource
 	for _, vol := range volumes {
-		used := inUse[vol.Name]
+		used := inUse.Uses(vol.Name, vol.Driver)
+		sharedName := len(drivers[vol.Name]) > 1
 
 		// Get detailed info
 		var labels map[string]string
 		var createdAt time.Time
 		var composeProject string
-		if inspect, ok := inspectByName[vol.Name]; ok {
+		mountpoint := vol.Mountpoint
+		anonymous := docker.IsAnonymousVolume(vol.Name)
+		if sharedName {
+			// Inspecting by name may describe the other driver's volume
+		} else if inspect, ok := inspectByName[vol.Name]; ok {
 			labels = inspect.Labels
+			if inspect.Mountpoint != "" {
+				mountpoint = inspect.Mountpoint
+			}
 			if t, err := time.Parse(time.RFC3339Nano, inspect.CreatedAt); err == nil {
 				createdAt = t
 			}
 			composeProject = docker.ComposeProjectFromLabels(labels)
-		} else if inspect, err := docker.InspectVolume(vol.Name); err == nil {
+			anonymous = docker.IsAnonymous(vol.Name, inspect)
+		} else if cfg.Fast {
+			// No inspect: labels come from list output, creation time is unknown
+			labels = vol.Labels
+			composeProject = docker.ComposeProjectFromLabels(labels)
+		} else if inspect, err := docker.InspectVolume(ctx, vol.Name); err == nil {
 			labels = inspect.Labels
 			if t, err := time.Parse(time.RFC3339Nano, inspect.CreatedAt); err == nil {
 				createdAt = t
 			}
 			composeProject = docker.ComposeProjectFromLabels(labels)
+			anonymous = docker.IsAnonymous(vol.Name, inspect)
+		} else if errors.Is(err, docker.ErrTimeout) {
+			return nil, err
 		}
 
 		// Apply filters
-		if cfg.OlderThan > 0 && !createdAt.IsZero() {
-			if time.Since(createdAt) < cfg.OlderThan {
+		if olderThan > 0 && !createdAt.IsZero() {
+			if time.Since(createdAt) < olderThan {
+				filtered.Add(TypeVolume, "--older-than")
 				continue // Skip: not old enough
 			}
 		}
 
+		if cfg.NewerThan > 0 && !createdAt.IsZero() {
+			if time.Since(createdAt) > cfg.NewerThan {
+				filtered.Add(TypeVolume, "--newer-than")
+				continue // Skip: too old
+			}
+		}
+
+		if cfg.IsAfterUntil(createdAt) {
+			filtered.Add(TypeVolume, "--until")
+			continue // Skip: created after the cutoff
+		}
+
+		if !cfg.MatchName(vol.Name) {
+			filtered.Add(TypeVolume, "--name")
+			continue // Skip: name does not match
+		}
+
+		if !cfg.MatchLabels(labels) {
+			filtered.Add(TypeVolume, "--label")
+			continue // Skip: missing labels
+		}
+
 		if cfg.Anonymous {
-			if !docker.IsAnonymousVolume(vol.Name) {
+			if !anonymous {
+				filtered.Add(TypeVolume, "--anonymous")
 				continue // Skip: not anonymous
 			}
 		}
 
-		category, protectReason := categorizeVolume(vol, used, labels, cfg)
+		category, protectReason := categorizeVolume(vol, used, sharedName, anonymous, labels, createdAt, activeProjects, cfg)
+		category = promoteUnused(category, cfg)
 
 		results = append(results, VolumeResource{
 			volume:         vol,
 			category:       category,
 			inUse:          used,
+			mountpoint:     mountpoint,
 			labels:         labels,
 			createdAt:      createdAt,
 			composeProject: composeProject,
 			protectReason:  protectReason,
+			anonymous:      anonymous,
 		})
 	}
 
+	if cfg.VolumeSizes {
+		measureVolumeSizes(results)
+	}
+
+	for i := range results {
+		results[i].risk = volumeRisk(&results[i])
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	return results, nil
 }
 
-func categorizeVolume(vol docker.Volume, inUse bool, labels map[string]string, cfg *config.Config) (Category, string) {
+// databaseVolume matches volume names that usually hold database files
+var databaseVolume = regexp.MustCompile(`(?i)(postgres|pgdata|mysql|mariadb|mongo|redis|elastic|cassandra|couch|influx|clickhouse|sqlite|(^|[-_])db($|[-_])|(^|[-_])data($|[-_]))`)
+
+// volumeRisk tells why deleting a named volume may lose data. Anonymous and
+// protected volumes are not flagged; neither are local volumes seen to be empty.
+func volumeRisk(v *VolumeResource) string {
+	if v.IsAnonymous() || v.IsProtected() {
+		return ""
+	}
+
+	name := v.volume.Name
+	if composeName := v.labels["com.docker.compose.volume"]; composeName != "" {
+		name = composeName
+	}
+	if databaseVolume.MatchString(name) {
+		return "may hold database data"
+	}
+
+	if v.size > 0 {
+		return "contains data"
+	}
+	if v.volume.Driver == "local" && v.mountpoint != "" {
+		if empty, ok := dirEmpty(v.mountpoint); ok {
+			if empty {
+				return ""
+			}
+			return "contains data"
+		}
+	}
+	// The mountpoint is unreadable or remote, so assume the worst
+	return "named volume may contain data"
+}
+
+// dirEmpty reports whether dir has no entries; ok is false when it cannot be read
+func dirEmpty(dir string) (empty, ok bool) {
+	f, err := os.Open(dir)
+	if err != nil {
+		return false, false
+	}
+	defer f.Close()
+
+	_, err = f.Readdirnames(1)
+	if err == io.EOF {
+		return true, true
+	}
+	return false, err == nil
+}
+
+// volumeSizeWorkers bounds how many volumes are walked at once
+const volumeSizeWorkers = 4
+
+// measureVolumeSizes sums the files under each local volume's mountpoint
+func measureVolumeSizes(volumes []VolumeResource) {
+	jobs := make(chan *VolumeResource)
+	var wg sync.WaitGroup
+
+	for w := 0; w < volumeSizeWorkers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for v := range jobs {
+				v.size = dirSize(v.mountpoint)
+			}
+		}()
+	}
+
+	for i := range volumes {
+		v := &volumes[i]
+		// Other drivers do not store data on the local filesystem
+		if v.volume.Driver != "local" || v.mountpoint == "" {
+			continue
+		}
+		jobs <- v
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// dirSize returns the total size of regular files under root.
+// Unreadable files and directories are skipped.
+func dirSize(root string) int64 {
+	var total int64
+	_ = filepath.WalkDir(root, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil || !d.Type().IsRegular() {
+			return nil
+		}
+		if info, err := d.Info(); err == nil {
+			total += info.Size()
+		}
+		return nil
+	})
+	return total
+}
+
+func categorizeVolume(vol docker.Volume, inUse, sharedName, anonymous bool, labels map[string]string, createdAt time.Time, activeProjects map[string]bool, cfg *config.Config) (Category, string) {
 	// Check protection label
-	if labels != nil && labels[docker.LabelProtect] == "true" {
+	if cfg.IsProtectedByLabel(labels) {
 		return CategoryProtected, "protected by label"
 	}
 
+	if cfg.IsTooRecent(createdAt) {
+		return CategoryProtected, "too recent"
+	}
+
+	if cfg.InGracePeriod(docker.ComposeProjectFromLabels(labels), createdAt) {
+		return CategoryProtected, "compose grace period"
+	}
+
+	if cfg.IsExcluded(vol.Name) {
+		return CategoryProtected, "excluded by pattern"
+	}
+
 	if inUse {
 		return CategoryProtected, "mounted by container"
 	}
 
+	// docker volume rm cannot tell which driver's volume to remove
+	if sharedName {
+		return CategoryProtected, "name shared with another driver"
+	}
+
+	if isActiveProject(labels, activeProjects) {
+		return CategoryProtected, "compose project running"
+	}
+
 	// Anonymous volumes are suggested for deletion
-	if docker.IsAnonymousVolume(vol.Name) {
+	if anonymous {
 		return CategorySuggested, ""
 	}
 
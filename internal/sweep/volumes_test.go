@@ -0,0 +1,55 @@
+package sweep
+
+import (
+	"testing"
+	"time"
+
+	"github.com/midnattsol/docker-sweep/internal/config"
+	"github.com/midnattsol/docker-sweep/internal/docker"
+)
+
+func TestCategorizeVolumeSharedName(t *testing.T) {
+	const anonymousName = "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	created := time.Now().Add(-30 * 24 * time.Hour)
+
+	tests := []struct {
+		name       string
+		volume     docker.Volume
+		inUse      bool
+		sharedName bool
+		anonymous  bool
+		want       Category
+		wantReason string
+	}{
+		{"anonymous", docker.Volume{Name: anonymousName, Driver: "local"}, false, false, true, CategorySuggested, ""},
+		{"anonymous shared name", docker.Volume{Name: anonymousName, Driver: "local"}, false, true, true, CategoryProtected, "name shared with another driver"},
+		{"named shared name", docker.Volume{Name: "data", Driver: "nfs"}, false, true, false, CategoryProtected, "name shared with another driver"},
+		{"mounted wins over shared name", docker.Volume{Name: "data", Driver: "local"}, true, true, false, CategoryProtected, "mounted by container"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, reason := categorizeVolume(tt.volume, tt.inUse, tt.sharedName, tt.anonymous, nil, created, nil, config.DefaultConfig())
+			if got != tt.want || reason != tt.wantReason {
+				t.Errorf("categorizeVolume() = %s %q, want %s %q", got, reason, tt.want, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestLimitSuggestedKeysVolumesByDriver(t *testing.T) {
+	older := time.Now().Add(-48 * time.Hour)
+	newer := time.Now().Add(-24 * time.Hour)
+	r := &Result{Volumes: []VolumeResource{
+		{volume: docker.Volume{Name: "data", Driver: "local"}, category: CategorySuggested, createdAt: older},
+		{volume: docker.Volume{Name: "data", Driver: "nfs"}, category: CategorySuggested, createdAt: newer},
+	}}
+
+	r.LimitSuggested(1)
+
+	if r.Volumes[0].category != CategorySuggested {
+		t.Errorf("oldest volume = %s, want suggested", r.Volumes[0].category)
+	}
+	if r.Volumes[1].category != CategoryUnused {
+		t.Errorf("same name on another driver = %s, want unused", r.Volumes[1].category)
+	}
+}
@@ -0,0 +1,138 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/midnattsol/docker-sweep/internal/sweep"
+)
+
+// ConfirmAction is the outcome of the confirmation screen
+type ConfirmAction int
+
+const (
+	ConfirmActionCancel ConfirmAction = iota
+	ConfirmActionProceed
+	ConfirmActionBack
+)
+
+// ConfirmModel is a bubbletea model that summarizes the selection before deletion
+type ConfirmModel struct {
+	resources  []sweep.Resource
+	termHeight int
+	action     ConfirmAction
+}
+
+// NewConfirm creates a confirmation screen for the selected resources
+func NewConfirm(resources []sweep.Resource) ConfirmModel {
+	return ConfirmModel{resources: resources}
+}
+
+func (m ConfirmModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m ConfirmModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.termHeight = msg.Height
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "y", "Y", "enter":
+			m.action = ConfirmActionProceed
+			return m, tea.Quit
+
+		case "esc", "n", "backspace":
+			m.action = ConfirmActionBack
+			return m, tea.Quit
+
+		case "q", "ctrl+c":
+			m.action = ConfirmActionCancel
+			return m, tea.Quit
+		}
+	}
+
+	return m, nil
+}
+
+func (m ConfirmModel) View() string {
+	var b strings.Builder
+
+	b.WriteString(RenderHeader())
+	b.WriteString(fmt.Sprintf("\n  %s\n\n", WarningStyle.Render(
+		fmt.Sprintf("Delete %d resources?", len(m.resources)))))
+
+	types := []sweep.ResourceType{sweep.TypeContainer, sweep.TypeImage, sweep.TypeVolume, sweep.TypeNetwork}
+	byType := make(map[sweep.ResourceType][]sweep.Resource)
+	var total int64
+	for _, r := range m.resources {
+		byType[r.Type()] = append(byType[r.Type()], r)
+		total += r.Size()
+	}
+
+	// Share the available lines between the types so the footer stays visible
+	height := m.termHeight
+	if height <= 0 {
+		height = 24
+	}
+	budget := (height - 12 - 2*len(byType)) / max(len(byType), 1)
+	if budget < 1 {
+		budget = 1
+	}
+
+	for _, t := range types {
+		resources := byType[t]
+		if len(resources) == 0 {
+			continue
+		}
+
+		var subtotal int64
+		for _, r := range resources {
+			subtotal += r.Size()
+		}
+		header := typeHeader(t, len(resources))
+		if subtotal > 0 {
+			header += " " + SizeStyle.Render(FormatSize(subtotal))
+		}
+		b.WriteString(fmt.Sprintf("  %s\n", header))
+
+		shown := resources
+		if len(shown) > budget {
+			shown = shown[:budget-1]
+		}
+		for _, r := range shown {
+			b.WriteString(fmt.Sprintf("    %s %s\n", CircleStyle.Render(), ResourceStyle.Render(r.DisplayName())))
+		}
+		if len(shown) < len(resources) {
+			b.WriteString(fmt.Sprintf("    %s\n", MutedStyle.Render(
+				fmt.Sprintf("... and %d more", len(resources)-len(shown)))))
+		}
+		b.WriteString("\n")
+	}
+
+	if total > 0 {
+		b.WriteString(fmt.Sprintf("  %s %s\n",
+			MutedStyle.Render("Space to recover:"),
+			SizeStyle.Render("~"+FormatSize(total))))
+	}
+
+	b.WriteString(fmt.Sprintf("\n  %s\n", Divider(60)))
+	b.WriteString(fmt.Sprintf("  %s\n\n", RenderHelp([][2]string{
+		{"y/↵", "delete"},
+		{"esc", "back"},
+		{"q", "quit"},
+	})))
+
+	return b.String()
+}
+
+// RunConfirm shows the confirmation screen and returns the chosen action
+func RunConfirm(resources []sweep.Resource) (ConfirmAction, error) {
+	finalModel, err := tea.NewProgram(NewConfirm(resources)).Run()
+	if err != nil {
+		return ConfirmActionCancel, err
+	}
+	return finalModel.(ConfirmModel).action, nil
+}
@@ -2,6 +2,7 @@ package ui
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -16,10 +17,24 @@ type PickerItem struct {
 	Disabled bool
 }
 
+// risky reports whether selecting the item needs a second, explicit keypress
+func (item PickerItem) risky() bool {
+	return !item.Disabled && sweep.GetRisk(item.Resource) != ""
+}
+
 // PickerModel is a bubbletea model for multi-select
 type PickerModel struct {
+	result               *sweep.Result // sizes the selection in updateTotalSize
 	items                []PickerItem
-	cursor               int
+	visible              []int // indexes into items matching filter
+	filter               string
+	filtering            bool // typing into the filter input
+	detail               bool // showing the detail view of the item under the cursor
+	help                 bool // showing the key and legend screen
+	groupByProject       bool // sections by Compose project instead of by type
+	cursor               int  // index into visible
+	visual               bool // marking a range from anchor to the cursor
+	anchor               int  // index into items where the visual range starts
 	scrollTop            int
 	termWidth            int
 	termHeight           int
@@ -28,6 +43,10 @@ type PickerModel struct {
 	toggleDangling       bool
 	enableDanglingToggle bool
 	showDangling         bool
+	showProtected        bool   // show why disabled rows are protected
+	groupLabel           string // sections by the value of this label instead of by type
+	riskArmed            string // key pressed once on risky items, "" when none
+	riskPending          int    // risky items the armed bulk key would add
 	totalSize            int64
 }
 
@@ -42,6 +61,39 @@ const (
 type PickerOptions struct {
 	EnableDanglingToggle bool
 	ShowDangling         bool
+
+	// ShowProtected replaces the details of protected rows with their protection reason
+	ShowProtected bool
+
+	// Confirm always shows the confirmation screen before returning the selection;
+	// otherwise it is shown when more than ConfirmThreshold resources are selected.
+	Confirm          bool
+	ConfirmThreshold int
+
+	// GroupBy sections the list by the value of this label key, with
+	// resources lacking it last; empty sections by type
+	GroupBy string
+
+	// Selection preselects the rows with these keys (see sweep.RowKeys)
+	// instead of the suggested ones when non-nil. Unknown keys are ignored
+	// and protected rows stay unselected.
+	Selection map[string]bool
+
+	// OnExit, when set, receives the selection the picker closed with
+	OnExit func(selected []sweep.Resource, cancelled bool)
+}
+
+func (o PickerOptions) exit(selected []sweep.Resource, cancelled bool) {
+	if o.OnExit != nil {
+		o.OnExit(selected, cancelled)
+	}
+}
+
+func (o PickerOptions) needsConfirm(selected int) bool {
+	if selected == 0 {
+		return false
+	}
+	return o.Confirm || (o.ConfirmThreshold > 0 && selected > o.ConfirmThreshold)
 }
 
 // NewPicker creates a new picker from sweep results
@@ -92,23 +144,177 @@ func NewPickerWithOptions(result *sweep.Result, opts PickerOptions) PickerModel
 		})
 	}
 
+	if opts.Selection != nil {
+		for i := range items {
+			items[i].Selected = !items[i].Disabled && opts.Selection[sweep.RowKeys(items[i].Resource)[0]]
+		}
+	}
+
+	// Risky resources are only ever selected one at a time, after a warning
+	for i := range items {
+		if items[i].risky() {
+			items[i].Selected = false
+		}
+	}
+
 	m := PickerModel{
+		result:               result,
 		items:                items,
 		enableDanglingToggle: opts.EnableDanglingToggle,
 		showDangling:         opts.ShowDangling,
+		showProtected:        opts.ShowProtected,
+		groupLabel:           opts.GroupBy,
 	}
+	m.applyFilter()
 	m.updateTotalSize()
 	return m
 }
 
+// applyFilter recomputes the visible items, keeping the cursor on the same
+// item when it still matches
+func (m *PickerModel) applyFilter() {
+	current := -1
+	if m.cursor >= 0 && m.cursor < len(m.visible) {
+		current = m.visible[m.cursor]
+	}
+
+	query := strings.ToLower(m.filter)
+	m.visible = make([]int, 0, len(m.items))
+	for i, item := range m.items {
+		if query == "" ||
+			strings.Contains(strings.ToLower(item.Resource.DisplayName()), query) ||
+			strings.Contains(strings.ToLower(m.detailsOf(item)), query) {
+			m.visible = append(m.visible, i)
+		}
+	}
+
+	if m.groupByProject || m.groupLabel != "" {
+		// Sections by project or label value, resources without one last;
+		// type order is kept inside
+		sort.SliceStable(m.visible, func(a, b int) bool {
+			pa := m.sectionOf(m.items[m.visible[a]])
+			pb := m.sectionOf(m.items[m.visible[b]])
+			if pa == "" || pb == "" {
+				return pa != "" && pb == ""
+			}
+			return pa < pb
+		})
+	}
+
+	m.cursor = 0
+	for vi, i := range m.visible {
+		if i == current {
+			m.cursor = vi
+			break
+		}
+	}
+	m.ensureCursorVisible()
+}
+
+// updateFilter handles keys while typing into the filter input
+func (m PickerModel) updateFilter(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyCtrlC:
+		m.quitting = true
+		return m, tea.Quit
+
+	case tea.KeyEsc:
+		m.filtering = false
+		m.filter = ""
+		m.applyFilter()
+
+	case tea.KeyEnter:
+		m.filtering = false
+
+	case tea.KeyBackspace:
+		if m.filter != "" {
+			runes := []rune(m.filter)
+			m.filter = string(runes[:len(runes)-1])
+			m.applyFilter()
+		}
+
+	case tea.KeyUp, tea.KeyDown:
+		m.filtering = false
+		return m.Update(msg)
+
+	case tea.KeyRunes, tea.KeySpace:
+		m.filter += string(msg.Runes)
+		m.applyFilter()
+	}
+
+	return m, nil
+}
+
 func (m *PickerModel) updateTotalSize() {
-	var total int64
+	var selected []sweep.Resource
 	for _, item := range m.items {
 		if item.Selected && !item.Disabled {
-			total += item.Resource.Size()
+			selected = append(selected, item.Resource)
+		}
+	}
+	m.totalSize = m.result.ReclaimableSize(selected)
+}
+
+// visualRange returns the visible positions spanned by the visual range, in
+// order. ok is false when the anchor was filtered out of view.
+func (m PickerModel) visualRange() (from, to int, ok bool) {
+	for vi, i := range m.visible {
+		if i == m.anchor {
+			from, to = vi, m.cursor
+			if from > to {
+				from, to = to, from
+			}
+			return from, to, true
+		}
+	}
+	return 0, 0, false
+}
+
+// toggleRange selects every deletable item of the visual range, or clears them
+// all when they are already selected, and leaves visual mode. Risky items are
+// cleared but never selected in bulk.
+func (m *PickerModel) toggleRange() {
+	m.visual = false
+	from, to, ok := m.visualRange()
+	if !ok {
+		return
+	}
+
+	allSelected := true
+	for _, i := range m.visible[from : to+1] {
+		if !m.items[i].Disabled && !m.items[i].risky() && !m.items[i].Selected {
+			allSelected = false
+			break
 		}
 	}
-	m.totalSize = total
+	for _, i := range m.visible[from : to+1] {
+		if !m.items[i].Disabled && (allSelected || !m.items[i].risky()) {
+			m.items[i].Selected = !allSelected
+		}
+	}
+	m.updateTotalSize()
+}
+
+// selectBulk adds the visible deletable items matching match to the selection.
+// Risky ones are only added when key is pressed twice in a row (armed == key).
+func (m *PickerModel) selectBulk(key, armed string, match func(PickerItem) bool) {
+	pending := 0
+	for _, i := range m.visible {
+		item := &m.items[i]
+		if item.Disabled || item.Selected || !match(*item) {
+			continue
+		}
+		if item.risky() && armed != key {
+			pending++
+			continue
+		}
+		item.Selected = true
+	}
+	if pending > 0 {
+		m.riskArmed = key
+		m.riskPending = pending
+	}
+	m.updateTotalSize()
 }
 
 func (m PickerModel) Init() tea.Cmd {
@@ -123,11 +329,106 @@ func (m PickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.ensureCursorVisible()
 
 	case tea.KeyMsg:
+		if m.filtering {
+			return m.updateFilter(msg)
+		}
+
+		if m.help {
+			// Any key dismisses the help screen
+			if msg.String() == "ctrl+c" {
+				m.quitting = true
+				return m, tea.Quit
+			}
+			m.help = false
+			return m, nil
+		}
+
+		if m.detail {
+			switch msg.String() {
+			case "ctrl+c":
+				m.quitting = true
+				return m, tea.Quit
+			case "esc", "i", "q":
+				m.detail = false
+			}
+			return m, nil
+		}
+
+		// A risky item needs two presses of space in a row
+		armed := m.riskArmed
+		m.riskArmed = ""
+
 		switch msg.String() {
-		case "q", "esc", "ctrl+c":
+		case "esc":
+			if m.visual {
+				m.visual = false
+				return m, nil
+			}
+			if m.filter != "" {
+				m.filter = ""
+				m.applyFilter()
+				return m, nil
+			}
 			m.quitting = true
 			return m, tea.Quit
 
+		case "q", "ctrl+c":
+			m.quitting = true
+			return m, tea.Quit
+
+		case "/":
+			m.filtering = true
+
+		case "?":
+			m.help = true
+
+		case "i":
+			if len(m.visible) > 0 {
+				m.detail = true
+			}
+
+		case "p":
+			// Select every deletable resource of the highlighted item's Compose project
+			if len(m.visible) == 0 {
+				break
+			}
+			project := sweep.GetComposeProject(m.items[m.visible[m.cursor]].Resource)
+			if project == "" {
+				break
+			}
+			for i := range m.items {
+				if !m.items[i].Disabled && !m.items[i].risky() && sweep.GetComposeProject(m.items[i].Resource) == project {
+					m.items[i].Selected = true
+				}
+			}
+			m.updateTotalSize()
+
+		case "l":
+			// Select every deletable resource of the highlighted item's label group
+			if m.groupLabel == "" || len(m.visible) == 0 {
+				break
+			}
+			value := m.labelOf(m.items[m.visible[m.cursor]])
+			for i := range m.items {
+				if !m.items[i].Disabled && !m.items[i].risky() && m.labelOf(m.items[i]) == value {
+					m.items[i].Selected = true
+				}
+			}
+			m.updateTotalSize()
+
+		case "P":
+			m.groupByProject = !m.groupByProject
+			m.applyFilter()
+
+		case "V":
+			// Mark the anchor of a range; space toggles everything up to the cursor
+			if m.visual || len(m.visible) == 0 {
+				m.visual = false
+				break
+			}
+			m.visual = true
+			m.anchor = m.visible[m.cursor]
+
 		case "d":
 			if m.enableDanglingToggle {
 				m.toggleDangling = true
@@ -141,13 +442,13 @@ func (m PickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "up", "k":
 			m.cursor--
 			if m.cursor < 0 {
-				m.cursor = len(m.items) - 1
+				m.cursor = len(m.visible) - 1
 			}
 			m.ensureCursorVisible()
 
 		case "down", "j":
 			m.cursor++
-			if m.cursor >= len(m.items) {
+			if m.cursor >= len(m.visible) {
 				m.cursor = 0
 			}
 			m.ensureCursorVisible()
@@ -163,37 +464,65 @@ func (m PickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.ensureCursorVisible()
 
 		case "end", "G":
-			m.cursor = len(m.items) - 1
+			m.cursor = len(m.visible) - 1
 			m.ensureCursorVisible()
 
 		case " ":
 			// Toggle selection
-			if !m.items[m.cursor].Disabled {
-				m.items[m.cursor].Selected = !m.items[m.cursor].Selected
+			if len(m.visible) == 0 {
+				break
+			}
+			if m.visual {
+				m.toggleRange()
+				break
+			}
+			item := &m.items[m.visible[m.cursor]]
+			if item.risky() && !item.Selected && armed != " " {
+				m.riskArmed = " "
+				break
+			}
+			if !item.Disabled {
+				item.Selected = !item.Selected
 				m.updateTotalSize()
 			}
 
 		case "a":
-			// Select all non-disabled
-			for i := range m.items {
-				if !m.items[i].Disabled {
+			// Select all visible non-disabled, leaving risky items to be picked one by one
+			for _, i := range m.visible {
+				if !m.items[i].Disabled && !m.items[i].risky() {
 					m.items[i].Selected = true
 				}
 			}
 			m.updateTotalSize()
 
+		case "u":
+			// Add every visible unused resource
+			m.selectBulk("u", armed, func(item PickerItem) bool {
+				return item.Resource.Category() == sweep.CategoryUnused
+			})
+
+		case "t":
+			// Add every visible resource of the highlighted item's type
+			if len(m.visible) == 0 {
+				break
+			}
+			typ := m.items[m.visible[m.cursor]].Resource.Type()
+			m.selectBulk("t", armed, func(item PickerItem) bool {
+				return item.Resource.Type() == typ
+			})
+
 		case "n":
-			// Select none
-			for i := range m.items {
+			// Select none of the visible
+			for _, i := range m.visible {
 				m.items[i].Selected = false
 			}
 			m.updateTotalSize()
 
 		case "s":
-			// Select only suggested
-			for i := range m.items {
+			// Select only suggested among the visible
+			for _, i := range m.visible {
 				if !m.items[i].Disabled {
-					m.items[i].Selected = m.items[i].Resource.IsSuggested()
+					m.items[i].Selected = m.items[i].Resource.IsSuggested() && !m.items[i].risky()
 				}
 			}
 			m.updateTotalSize()
@@ -205,6 +534,21 @@ func (m PickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 func (m PickerModel) View() string {
 	var b strings.Builder
+
+	if m.detail {
+		b.WriteString(RenderHeader())
+		b.WriteString(RenderDetail(m.items[m.visible[m.cursor]].Resource))
+		b.WriteString(fmt.Sprintf("\n  %s\n", Divider(60)))
+		b.WriteString(fmt.Sprintf("  %s\n\n", RenderHelp([][2]string{{"esc", "back"}})))
+		return b.String()
+	}
+	if m.help {
+		b.WriteString(RenderHeader())
+		b.WriteString(m.renderHelpScreen())
+		b.WriteString(fmt.Sprintf("\n  %s\n", Divider(60)))
+		b.WriteString(fmt.Sprintf("  %s\n\n", MutedStyle.Render("Press any key to return")))
+		return b.String()
+	}
 	widths := m.computeColumnWidths()
 	rows := m.renderRows(widths)
 
@@ -223,15 +567,18 @@ func (m PickerModel) View() string {
 		m.scrollTop = 0
 	}
 
-	b.WriteString(RenderHeader())
-	b.WriteString(fmt.Sprintf("\n  %s\n", MutedStyle.Render("Select resources to delete:")))
-	b.WriteString("\n")
-
 	start := m.scrollTop
 	end := m.scrollTop + viewportHeight
 	if end > len(rows) {
 		end = len(rows)
 	}
+	if m.compact() {
+		return m.compactView(rows[start:end], start, len(rows))
+	}
+
+	b.WriteString(RenderHeader())
+	b.WriteString(fmt.Sprintf("\n  %s\n", MutedStyle.Render("Select resources to delete:")))
+	b.WriteString("\n")
 	for _, row := range rows[start:end] {
 		b.WriteString(row + "\n")
 	}
@@ -241,6 +588,9 @@ func (m PickerModel) View() string {
 			fmt.Sprintf("Showing %d-%d of %d", start+1, end, len(rows)),
 		)))
 	}
+	if len(m.visible) == 0 {
+		b.WriteString(fmt.Sprintf("  %s\n", MutedStyle.Render("No resources match the filter")))
+	}
 
 	// Footer with help and stats
 	b.WriteString(fmt.Sprintf("\n  %s\n", Divider(60)))
@@ -249,16 +599,62 @@ func (m PickerModel) View() string {
 		{"␣", "toggle"},
 		{"pgup/pgdn", "scroll"},
 		{"a", "all"},
+		{"/", "filter"},
+		{"i", "details"},
+		{"p", "project"},
+		{"P", "group by project"},
+	}
+	if m.groupLabel != "" {
+		helpItems = append(helpItems, [2]string{"l", "label group"})
+	}
+	helpItems = append(helpItems, [][2]string{
+		{"V", "range"},
 		{"s", "suggested"},
+		{"u", "unused"},
+		{"t", "type"},
 		{"↵", "confirm"},
 		{"q", "quit"},
-	}
+		{"?", "help"},
+	}...)
 	if m.enableDanglingToggle {
 		helpItems = append(helpItems, [2]string{"d", "dangling"})
 	}
 	help := RenderHelp(helpItems)
 	b.WriteString(fmt.Sprintf("  %s\n", help))
 
+	if m.filtering || m.filter != "" {
+		cursor := ""
+		if m.filtering {
+			cursor = "█"
+		}
+		hint := "(esc to clear)"
+		if m.filtering {
+			hint = "(enter to apply, esc to clear)"
+		}
+		b.WriteString(fmt.Sprintf("  %s %s%s %s\n",
+			MutedStyle.Render("Filter:"),
+			BoldStyle.Render("/"+m.filter), cursor,
+			MutedStyle.Render(hint)))
+	}
+
+	switch m.riskArmed {
+	case "":
+	case " ":
+		item := m.items[m.visible[m.cursor]]
+		b.WriteString(fmt.Sprintf("  %s\n", WarningStyle.Render(fmt.Sprintf(
+			"⚠ %s %s; press space again to select it", item.Resource.DisplayName(), sweep.GetRisk(item.Resource)))))
+	default:
+		b.WriteString(fmt.Sprintf("  %s\n", WarningStyle.Render(fmt.Sprintf(
+			"⚠ %d more may hold data; press %s again to include them", m.riskPending, m.riskArmed))))
+	}
+
+	if m.visual {
+		if from, to, ok := m.visualRange(); ok {
+			b.WriteString(fmt.Sprintf("  %s\n", MutedStyle.Render(
+				fmt.Sprintf("Range: %d items (space to toggle, esc to cancel)", to-from+1))))
+		}
+	}
+
 	if m.enableDanglingToggle {
 		state := "hidden"
 		if m.showDangling {
@@ -279,8 +675,44 @@ func (m PickerModel) View() string {
 	return b.String()
 }
 
+// compactView draws the list rows and a single status line, without the
+// header or the space to recover, and without a trailing newline so that
+// every line of a tiny terminal is used
+func (m PickerModel) compactView(rows []string, start, total int) string {
+	lines := append([]string{}, rows...)
+	if m.termHeight <= 1 {
+		return strings.Join(lines, "\n")
+	}
+
+	var status string
+	switch {
+	case m.filtering || m.filter != "":
+		cursor := ""
+		if m.filtering {
+			cursor = "█"
+		}
+		status = MutedStyle.Render("Filter:") + " " + BoldStyle.Render("/"+m.filter) + cursor
+	case m.riskArmed == " ":
+		status = WarningStyle.Render("⚠ press space again to select it")
+	case m.riskArmed != "":
+		status = WarningStyle.Render(fmt.Sprintf("⚠ press %s again to include %d more", m.riskArmed, m.riskPending))
+	case m.visual:
+		if from, to, ok := m.visualRange(); ok {
+			status = MutedStyle.Render(fmt.Sprintf("Range: %d items", to-from+1))
+		}
+	default:
+		position := "No resources match the filter"
+		if total > 0 {
+			position = fmt.Sprintf("%d-%d of %d", start+1, start+len(rows), total)
+		}
+		status = MutedStyle.Render(position) + "  " + RenderHelp([][2]string{{"↵", "confirm"}, {"q", "quit"}, {"?", "help"}})
+	}
+	lines = append(lines, "  "+status)
+	return strings.Join(lines, "\n")
+}
+
 func (m *PickerModel) moveCursorBy(delta int) {
-	if len(m.items) == 0 {
+	if len(m.visible) == 0 {
 		return
 	}
 	if delta == 0 {
@@ -290,33 +722,64 @@ func (m *PickerModel) moveCursorBy(delta int) {
 	if m.cursor < 0 {
 		m.cursor = 0
 	}
-	if m.cursor >= len(m.items) {
-		m.cursor = len(m.items) - 1
+	if m.cursor >= len(m.visible) {
+		m.cursor = len(m.visible) - 1
 	}
 	m.ensureCursorVisible()
 }
 
+// minPickerRows is the fewest list rows the full layout is drawn with;
+// shorter terminals get the compact layout
+const minPickerRows = 5
+
 func (m *PickerModel) listViewportHeight() int {
+	if m.compact() {
+		// One footer line, unless it would leave no room for the list
+		if m.termHeight > 1 {
+			return m.termHeight - 1
+		}
+		return 1
+	}
+
 	height := m.termHeight
 	if height <= 0 {
 		height = 24
 	}
 
+	viewport := height - m.reservedRows()
+	if viewport < minPickerRows {
+		viewport = minPickerRows
+	}
+
+	return viewport
+}
+
+// compact reports whether the terminal is too short for the header and
+// footer around minPickerRows of list, so the list gets all but one line
+func (m *PickerModel) compact() bool {
+	return m.termHeight > 0 && m.termHeight-m.reservedRows() < minPickerRows
+}
+
+// reservedRows counts the lines the full layout draws around the list
+func (m *PickerModel) reservedRows() int {
 	reserved := 11
 	if m.totalSize > 0 {
 		reserved++
 	}
-
-	viewport := height - reserved
-	if viewport < 5 {
-		viewport = 5
+	if m.filtering || m.filter != "" {
+		reserved++
 	}
-
-	return viewport
+	if m.visual {
+		reserved++
+	}
+	if m.riskArmed != "" {
+		reserved++
+	}
+	return reserved
 }
 
 func (m *PickerModel) ensureCursorVisible() {
-	if len(m.items) == 0 {
+	if len(m.visible) == 0 {
 		m.scrollTop = 0
 		return
 	}
@@ -345,13 +808,14 @@ func (m *PickerModel) ensureCursorVisible() {
 
 func (m PickerModel) totalRows() int {
 	rows := 0
-	currentType := sweep.ResourceType("")
-	for _, item := range m.items {
-		if item.Resource.Type() != currentType {
-			if currentType != "" {
+	currentSection := ""
+	for vi, i := range m.visible {
+		section := m.sectionOf(m.items[i])
+		if vi == 0 || section != currentSection {
+			if vi > 0 {
 				rows++ // blank separator row between sections
 			}
-			currentType = item.Resource.Type()
+			currentSection = section
 			rows++
 		}
 		rows++
@@ -359,22 +823,24 @@ func (m PickerModel) totalRows() int {
 	return rows
 }
 
+// rowIndexForItem maps an index into the visible items to its rendered row
 func (m PickerModel) rowIndexForItem(itemIndex int) int {
 	if itemIndex < 0 {
 		return 0
 	}
-	if itemIndex >= len(m.items) {
-		itemIndex = len(m.items) - 1
+	if itemIndex >= len(m.visible) {
+		itemIndex = len(m.visible) - 1
 	}
 
 	row := 0
-	currentType := sweep.ResourceType("")
-	for i, item := range m.items {
-		if item.Resource.Type() != currentType {
-			if currentType != "" {
+	currentSection := ""
+	for i, idx := range m.visible {
+		section := m.sectionOf(m.items[idx])
+		if i == 0 || section != currentSection {
+			if i > 0 {
 				row++ // blank separator row
 			}
-			currentType = item.Resource.Type()
+			currentSection = section
 			row++
 		}
 		if i == itemIndex {
@@ -388,30 +854,42 @@ func (m PickerModel) rowIndexForItem(itemIndex int) int {
 
 func (m PickerModel) renderRows(widths pickerColumnWidths) []string {
 	rows := make([]string, 0, m.totalRows())
-	currentType := sweep.ResourceType("")
-
-	for i, item := range m.items {
-		if item.Resource.Type() != currentType {
-			if currentType != "" {
+	currentSection := ""
+	rangeFrom, rangeTo, inVisual := m.visualRange()
+	inVisual = inVisual && m.visual
+
+	for i, idx := range m.visible {
+		item := m.items[idx]
+		if section := m.sectionOf(item); i == 0 || section != currentSection {
+			if i > 0 {
 				rows = append(rows, "")
 			}
-			currentType = item.Resource.Type()
-			count := m.countByType(currentType)
-			rows = append(rows, fmt.Sprintf("  %s", typeHeader(currentType, count)))
+			currentSection = section
+			count := m.countBySection(section)
+			switch {
+			case m.groupByProject:
+				rows = append(rows, fmt.Sprintf("  %s", projectHeader(section, count)))
+			case m.groupLabel != "":
+				rows = append(rows, fmt.Sprintf("  %s", labelHeader(m.groupLabel, section, count, m.sizeBySection(section))))
+			default:
+				rows = append(rows, fmt.Sprintf("  %s", typeHeader(item.Resource.Type(), count)))
+			}
 		}
 
 		cursor := "  "
 		if i == m.cursor {
 			cursor = CursorStyle.Render() + " "
+		} else if inVisual && i >= rangeFrom && i <= rangeTo {
+			cursor = MutedStyle.Render("┃") + " "
 		}
 
 		var checkbox string
 		if item.Disabled {
-			checkbox = MutedStyle.Render("▢")
+			checkbox = MutedStyle.Render(active.CheckProtected)
 		} else if item.Selected {
-			checkbox = SuccessStyle.Render("▣")
+			checkbox = SuccessStyle.Render(active.CheckSelected)
 		} else {
-			checkbox = "▢"
+			checkbox = active.CheckUnselected
 		}
 
 		name := item.Resource.DisplayName()
@@ -423,9 +901,11 @@ func (m PickerModel) renderRows(widths pickerColumnWidths) []string {
 			name = ResourceStyle.Render(name)
 		}
 
-		details := item.Resource.Details()
+		details := m.detailsOf(item)
 		if item.Disabled {
 			details = ProtectedStyle.Render(details)
+		} else if item.risky() {
+			details = WarningStyle.Render(details)
 		} else {
 			details = MutedStyle.Render(details)
 		}
@@ -465,6 +945,20 @@ type pickerColumnWidths struct {
 	compose int
 }
 
+// detailsOf returns the details column of an item: its status, or the
+// protection reason of protected items when showProtected is set
+func (m PickerModel) detailsOf(item PickerItem) string {
+	if m.showProtected && item.Disabled {
+		if reason := sweep.GetProtectReason(item.Resource); reason != "" {
+			return "protected: " + reason
+		}
+	}
+	if item.risky() {
+		return "⚠ " + item.Resource.Details()
+	}
+	return item.Resource.Details()
+}
+
 func (m PickerModel) computeColumnWidths() pickerColumnWidths {
 	var w pickerColumnWidths
 
@@ -474,7 +968,7 @@ func (m PickerModel) computeColumnWidths() pickerColumnWidths {
 			w.name = nameWidth
 		}
 
-		detailsWidth := lipgloss.Width(item.Resource.Details())
+		detailsWidth := lipgloss.Width(m.detailsOf(item))
 		if detailsWidth > w.details {
 			w.details = detailsWidth
 		}
@@ -517,16 +1011,128 @@ func padLeft(s string, width int) string {
 	return strings.Repeat(" ", pad) + s
 }
 
-func (m PickerModel) countByType(t sweep.ResourceType) int {
+// sectionOf returns the section an item is listed under: its type, its
+// Compose project when grouping by project, or its --group-by label value
+func (m PickerModel) sectionOf(item PickerItem) string {
+	switch {
+	case m.groupByProject:
+		return sweep.GetComposeProject(item.Resource)
+	case m.groupLabel != "":
+		return m.labelOf(item)
+	}
+	return string(item.Resource.Type())
+}
+
+// labelOf returns the value of the --group-by label, "" when it is not set
+func (m PickerModel) labelOf(item PickerItem) string {
+	return sweep.GetLabels(item.Resource)[m.groupLabel]
+}
+
+// sizeBySection sums the sizes of the deletable items of a section
+func (m PickerModel) sizeBySection(section string) int64 {
+	var size int64
+	for _, i := range m.visible {
+		item := m.items[i]
+		if m.sectionOf(item) == section && !item.Disabled {
+			size += item.Resource.Size()
+		}
+	}
+	return size
+}
+
+func (m PickerModel) countBySection(section string) int {
 	count := 0
-	for _, item := range m.items {
-		if item.Resource.Type() == t && !item.Disabled {
+	for _, i := range m.visible {
+		item := m.items[i]
+		if m.sectionOf(item) == section && !item.Disabled {
 			count++
 		}
 	}
 	return count
 }
 
+// renderHelpScreen explains every key and what the rows of the list show
+func (m PickerModel) renderHelpScreen() string {
+	keys := [][2]string{
+		{"↑/k ↓/j", "move the cursor"},
+		{"pgup/pgdn", "scroll a page (also ctrl+b/ctrl+f)"},
+		{"g G", "jump to the first or last item"},
+		{"space", "toggle the item (press twice for ⚠ items)"},
+		{"V", "mark a range; space toggles everything up to the cursor"},
+		{"a", "select everything visible except ⚠ items"},
+		{"s", "select only the suggested items"},
+		{"u", "add the unused items (press twice to include ⚠ items)"},
+		{"t", "add every item of the cursor's type"},
+		{"n", "select nothing"},
+		{"p", "select the cursor's Compose project"},
+		{"P", "group the list by Compose project"},
+	}
+	if m.groupLabel != "" {
+		keys = append(keys, [2]string{"l", "select the cursor's --group-by label group"})
+	}
+	keys = append(keys, [][2]string{
+		{"/", "filter by name or details (esc clears)"},
+		{"i", "show the details of the item"},
+	}...)
+	if m.enableDanglingToggle {
+		keys = append(keys, [2]string{"d", "show or hide dangling images"})
+	}
+	keys = append(keys,
+		[2]string{"enter", "delete the selection"},
+		[2]string{"q/esc", "quit without deleting"},
+		[2]string{"?", "this help"},
+	)
+
+	legend := [][2]string{
+		{SuccessStyle.Render(active.CheckSelected), "selected for deletion"},
+		{active.CheckUnselected, "not selected"},
+		{MutedStyle.Render(active.CheckProtected), "protected, cannot be selected (--show-protected says why)"},
+		{SizeStyle.Render("size"), "disk space the resource takes"},
+		{ProtectedStyle.Render("status"), "status of protected resources, in italics"},
+		{WarningStyle.Render("⚠ status"), "may hold data; selected only on purpose"},
+		{MutedStyle.Render("[project]"), "Compose project the resource belongs to"},
+	}
+
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf("\n  %s\n\n", BoldStyle.Render("Keys")))
+	for _, k := range keys {
+		b.WriteString(fmt.Sprintf("    %s %s\n", padRight(KeyStyle.Render(k[0]), 11), MutedStyle.Render(k[1])))
+	}
+	b.WriteString(fmt.Sprintf("\n  %s\n\n", BoldStyle.Render("Legend")))
+	for _, l := range legend {
+		b.WriteString(fmt.Sprintf("    %s %s\n", padRight(l[0], 11), MutedStyle.Render(l[1])))
+	}
+	return b.String()
+}
+
+func projectHeader(project string, count int) string {
+	name := project
+	if name == "" {
+		name = "No Compose project"
+	}
+	return fmt.Sprintf("%s %s %s",
+		"🧩",
+		BoldStyle.Render(name),
+		MutedStyle.Render(fmt.Sprintf("(%d)", count)))
+}
+
+// labelHeader names a --group-by section, like "env=ci", with its count and
+// the size of its deletable items
+func labelHeader(key, value string, count int, size int64) string {
+	name := key + "=" + value
+	if value == "" {
+		name = "unlabeled"
+	}
+	stats := fmt.Sprintf("(%d)", count)
+	if size > 0 {
+		stats = fmt.Sprintf("(%d · %s)", count, FormatSize(size))
+	}
+	return fmt.Sprintf("%s %s %s",
+		"🏷",
+		BoldStyle.Render(name),
+		MutedStyle.Render(stats))
+}
+
 func typeHeader(t sweep.ResourceType, count int) string {
 	var icon, name string
 	switch t {
@@ -567,12 +1173,12 @@ func (m PickerModel) SelectedResources() []sweep.Resource {
 			selected = append(selected, item.Resource)
 		}
 	}
-	return selected
+	return sweep.Dedupe(selected)
 }
 
 // RunPicker runs the interactive picker and returns selected resources
-func RunPicker(result *sweep.Result) ([]sweep.Resource, error) {
-	selected, action, err := RunPickerWithOptions(result, PickerOptions{})
+func RunPicker(result *sweep.Result, opts PickerOptions) ([]sweep.Resource, error) {
+	selected, action, err := RunPickerWithOptions(result, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -584,21 +1190,47 @@ func RunPicker(result *sweep.Result) ([]sweep.Resource, error) {
 
 func RunPickerWithOptions(result *sweep.Result, opts PickerOptions) ([]sweep.Resource, PickerAction, error) {
 	m := NewPickerWithOptions(result, opts)
-	p := tea.NewProgram(m)
 
-	finalModel, err := p.Run()
-	if err != nil {
-		return nil, PickerActionCancel, err
-	}
+	for {
+		p := tea.NewProgram(m)
 
-	fm := finalModel.(PickerModel)
-	if fm.Cancelled() {
-		return nil, PickerActionCancel, nil
-	}
+		finalModel, err := p.Run()
+		if err != nil {
+			return nil, PickerActionCancel, err
+		}
 
-	if fm.ToggleDanglingRequested() {
-		return nil, PickerActionToggleDangling, nil
-	}
+		fm := finalModel.(PickerModel)
+		if fm.Cancelled() {
+			opts.exit(fm.SelectedResources(), true)
+			return nil, PickerActionCancel, nil
+		}
+
+		if fm.ToggleDanglingRequested() {
+			return nil, PickerActionToggleDangling, nil
+		}
+
+		selected := fm.SelectedResources()
+		if !opts.needsConfirm(len(selected)) {
+			opts.exit(selected, false)
+			return selected, PickerActionConfirm, nil
+		}
 
-	return fm.SelectedResources(), PickerActionConfirm, nil
+		action, err := RunConfirm(selected)
+		if err != nil {
+			return nil, PickerActionCancel, err
+		}
+
+		switch action {
+		case ConfirmActionBack:
+			// Reopen the picker with the same cursor and selection
+			fm.confirmed = false
+			m = fm
+		case ConfirmActionCancel:
+			opts.exit(selected, true)
+			return nil, PickerActionCancel, nil
+		default:
+			opts.exit(selected, false)
+			return selected, PickerActionConfirm, nil
+		}
+	}
 }
@@ -0,0 +1,39 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/midnattsol/docker-sweep/internal/sweep"
+)
+
+func TestPickerShortTerminals(t *testing.T) {
+	result := &sweep.Result{
+		Containers: make([]sweep.ContainerResource, 12),
+		Images:     make([]sweep.ImageResource, 8),
+		Volumes:    make([]sweep.VolumeResource, 4),
+	}
+
+	for height := 1; height <= 10; height++ {
+		model, _ := NewPicker(result).Update(tea.WindowSizeMsg{Width: 80, Height: height})
+		m := model.(PickerModel)
+
+		for step := 0; step <= len(m.items); step++ {
+			viewport := m.listViewportHeight()
+			if viewport < 1 {
+				t.Fatalf("height %d: %d list rows visible, want at least 1", height, viewport)
+			}
+			row := m.rowIndexForItem(m.cursor)
+			if row < m.scrollTop || row >= m.scrollTop+viewport {
+				t.Fatalf("height %d, step %d: cursor row %d outside rows %d-%d", height, step, row, m.scrollTop, m.scrollTop+viewport-1)
+			}
+			if lines := len(strings.Split(m.View(), "\n")); lines > height {
+				t.Fatalf("height %d, step %d: view has %d lines", height, step, lines)
+			}
+
+			model, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+			m = model.(PickerModel)
+		}
+	}
+}
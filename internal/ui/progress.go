@@ -0,0 +1,165 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/progress"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+// ProgressUpdate reports how many of total steps have settled
+type ProgressUpdate struct {
+	Done  int
+	Total int
+}
+
+// progressClosedMsg signals the update channel was closed
+type progressClosedMsg struct{}
+
+// ProgressBarModel is a bubbletea model rendering "message [####----] done/total"
+type ProgressBarModel struct {
+	bar      progress.Model
+	message  string
+	done     int
+	total    int
+	updates  <-chan ProgressUpdate
+	cancel   context.CancelFunc
+	finished bool
+	stopping bool
+	err      error
+}
+
+// NewProgressBar creates a progress bar for total steps, advanced by updates.
+// Quitting calls cancel and keeps the bar up until the work is done.
+func NewProgressBar(message string, total int, updates <-chan ProgressUpdate, cancel context.CancelFunc) ProgressBarModel {
+	// The bar takes a color string, so colorless themes drop colors altogether
+	fill, profile := "", termenv.Ascii
+	if c, ok := active.Title.(lipgloss.Color); ok {
+		fill, profile = string(c), lipgloss.ColorProfile()
+	}
+	bar := progress.New(progress.WithSolidFill(fill), progress.WithColorProfile(profile), progress.WithoutPercentage(), progress.WithWidth(30))
+	bar.Full = '#'
+	bar.Empty = '-'
+	return ProgressBarModel{
+		bar:     bar,
+		message: message,
+		total:   total,
+		updates: updates,
+		cancel:  cancel,
+	}
+}
+
+// waitForUpdate reads the next update from the channel
+func (m ProgressBarModel) waitForUpdate() tea.Cmd {
+	return func() tea.Msg {
+		u, ok := <-m.updates
+		if !ok {
+			return progressClosedMsg{}
+		}
+		return u
+	}
+}
+
+func (m ProgressBarModel) Init() tea.Cmd {
+	return m.waitForUpdate()
+}
+
+func (m ProgressBarModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "esc", "ctrl+c":
+			// Work in flight can't be abandoned: ask it to stop and wait
+			if !m.stopping {
+				m.stopping = true
+				m.cancel()
+			}
+		}
+
+	case ProgressUpdate:
+		m.done, m.total = msg.Done, msg.Total
+		return m, m.waitForUpdate()
+
+	case progressClosedMsg:
+		return m, nil
+
+	case SpinnerDoneMsg:
+		m.finished = true
+		m.err = msg.Err
+		return m, tea.Quit
+	}
+
+	return m, nil
+}
+
+func (m ProgressBarModel) View() string {
+	if m.finished {
+		if m.err != nil {
+			return fmt.Sprintf("  %s %s\n", CrossStyle.Render(), m.message)
+		}
+		return fmt.Sprintf("  %s %s\n", CheckStyle.Render(), m.message)
+	}
+
+	message := m.message
+	if m.stopping {
+		message = "Stopping after the removals in progress..."
+	}
+	percent := 0.0
+	if m.total > 0 {
+		percent = float64(m.done) / float64(m.total)
+	}
+	return fmt.Sprintf("  %s [%s] %s\n",
+		MutedStyle.Render(message),
+		m.bar.ViewAs(percent),
+		MutedStyle.Render(fmt.Sprintf("%d/%d", m.done, m.total)))
+}
+
+// RunWithProgress runs fn while showing a progress bar for total steps. fn
+// receives a callback to report progress, which is safe to call from any
+// goroutine. Without a terminal it behaves like RunWithSpinner and the
+// callback does nothing.
+//
+// The context passed to fn is cancelled when the user quits the bar or ctx
+// is done; RunWithProgress always waits for fn to return and returns its
+// error, so fn decides how to stop.
+func RunWithProgress(ctx context.Context, message string, total int, fn func(ctx context.Context, progress func(done, total int)) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	if silent || quiet || !IsTTY() {
+		return RunWithSpinner(message, func() error {
+			return fn(ctx, func(int, int) {})
+		})
+	}
+
+	updates := make(chan ProgressUpdate)
+	stopped := make(chan struct{}) // closed once the bar no longer reads updates
+	done := make(chan error, 1)
+	p := tea.NewProgram(NewProgressBar(message, total, updates, cancel))
+
+	go func() {
+		err := fn(ctx, func(done, total int) {
+			select {
+			case updates <- ProgressUpdate{Done: done, Total: total}:
+			case <-stopped:
+			}
+		})
+		close(updates)
+		done <- err
+		p.Send(SpinnerDoneMsg{Err: err})
+	}()
+
+	_, runErr := p.Run()
+	close(stopped)
+	// The bar also quits on SIGINT or SIGTERM: stop fn as Ctrl+C would
+	cancel()
+	err := <-done
+	if runErr != nil && !errors.Is(runErr, tea.ErrInterrupted) {
+		return runErr
+	}
+	return err
+}
@@ -2,34 +2,69 @@ package ui
 
 import (
 	"fmt"
+	"sort"
+	"strings"
+	"time"
 
-	"github.com/charmbracelet/lipgloss"
+	"github.com/midnattsol/docker-sweep/internal/docker"
 	"github.com/midnattsol/docker-sweep/internal/sweep"
 )
 
+// quiet reduces output to essential lines: no header, spinners or decoration
+var quiet bool
+
+// SetQuiet turns quiet output on or off.
+func SetQuiet(q bool) {
+	quiet = q
+}
+
+// IsQuiet reports whether quiet output is on.
+func IsQuiet() bool {
+	return quiet
+}
+
 // RenderHeader renders the header for docker sweep.
 func RenderHeader() string {
+	if quiet {
+		return ""
+	}
 	title := TitleStyle.Render("docker-sweep")
+	if endpoint := docker.Endpoint(); endpoint != "" {
+		title += " " + MutedStyle.Render(docker.RuntimeVersion().String()+" · "+endpoint)
+	}
 	return fmt.Sprintf("\n  %s\n", title)
 }
 
 // RenderSummary renders summary after deletion.
 func RenderSummary(deleted int, total int) string {
+	if quiet {
+		return fmt.Sprintf("Deleted %d of %d resources\n", deleted, total)
+	}
+
 	content := fmt.Sprintf("Deleted %s of %s resources",
 		SuccessStyle.Render(fmt.Sprintf("%d", deleted)),
 		BoldStyle.Render(fmt.Sprintf("%d", total)))
 
-	box := lipgloss.NewStyle().
-		Border(lipgloss.RoundedBorder()).
-		BorderForeground(DarkGray).
-		Padding(0, 1).
-		Render(content)
+	box := BoxStyle.Render(content)
 
 	return fmt.Sprintf("\n%s\n\n", Indent(box, 2))
 }
 
+// RenderInterrupted renders the note printed after RenderSummary when an
+// interrupt stopped the deletion before skipped resources were attempted.
+func RenderInterrupted(skipped int) string {
+	msg := fmt.Sprintf("Interrupted: %d not attempted", skipped)
+	if quiet {
+		return msg + "\n"
+	}
+	return fmt.Sprintf("  %s %s\n\n", WarningStyle.Render("●"), WarningStyle.Render(msg))
+}
+
 // RenderError renders an error message.
 func RenderError(msg string) string {
+	if quiet {
+		return fmt.Sprintf("%s %s\n", CrossStyle.Render(), ErrorStyle.Render(msg))
+	}
 	return fmt.Sprintf("\n  %s %s\n\n", CrossStyle.Render(), ErrorStyle.Render(msg))
 }
 
@@ -38,36 +73,262 @@ func RenderErrorInline(msg string) string {
 	return fmt.Sprintf("%s %s", CrossStyle.Render(), ErrorStyle.Render(msg))
 }
 
+// RenderProgress renders a plain deletion progress line.
+func RenderProgress(done, total int) string {
+	return fmt.Sprintf("  %s %s\n", MutedStyle.Render("●"), MutedStyle.Render(fmt.Sprintf("Deleted %d/%d...", done, total)))
+}
+
+// RenderWatch renders a --watch status line, like "Next sweep at 15:04:05".
+func RenderWatch(msg string) string {
+	if quiet {
+		return msg + "\n"
+	}
+	return fmt.Sprintf("  %s %s\n", MutedStyle.Render("●"), MutedStyle.Render(msg))
+}
+
+// RenderUpdateNotice renders the one-line notice that a newer release exists.
+func RenderUpdateNotice(tag string) string {
+	return fmt.Sprintf("\n  %s A new version %s is available; run %s\n\n",
+		WarningStyle.Render("●"), SuccessStyle.Render(tag), BoldStyle.Render("docker sweep update"))
+}
+
 // RenderNoResources renders message when no resources are available for deletion.
 func RenderNoResources() string {
+	if quiet {
+		return "No resources to delete.\n"
+	}
 	return fmt.Sprintf("\n  %s %s\n\n", CheckStyle.Render(), MutedStyle.Render("No resources to delete."))
 }
 
-// RenderDryRun renders what would be deleted in dry-run mode.
-func RenderDryRun(resources []sweep.Resource) string {
+// RenderFiltered renders how many resources each filter excluded.
+func RenderFiltered(f *sweep.Filtered) string {
+	if f.IsEmpty() {
+		return ""
+	}
+
+	s := fmt.Sprintf("\n  %s\n", MutedStyle.Render("Skipped by filters:"))
+	types := []sweep.ResourceType{sweep.TypeContainer, sweep.TypeImage, sweep.TypeVolume, sweep.TypeNetwork}
+	for _, t := range types {
+		for _, c := range f.Counts(t) {
+			s += fmt.Sprintf("    %s %s %s\n",
+				CircleStyle.Render(),
+				BoldStyle.Render(fmt.Sprintf("%d %ss", c.Count, t)),
+				MutedStyle.Render("skipped by "+c.Filter))
+		}
+	}
+
+	return s
+}
+
+// RenderProtected lists protected resources with the reason they are kept.
+func RenderProtected(resources []sweep.Resource) string {
+	if len(resources) == 0 {
+		return ""
+	}
+
 	var s string
-	s += fmt.Sprintf("\n  %s\n\n", WarningStyle.Render("Dry run - would delete:"))
+	if quiet {
+		for _, r := range resources {
+			s += fmt.Sprintf("protected %s %s: %s\n", r.Type(), r.DisplayName(), sweep.GetProtectReason(r))
+		}
+		return s
+	}
 
+	s = fmt.Sprintf("\n  %s\n", MutedStyle.Render("Protected:"))
 	for _, r := range resources {
-		s += fmt.Sprintf("    %s %s %s\n",
+		s += fmt.Sprintf("    %s %s %s %s\n",
 			CircleStyle.Render(),
 			ResourceStyle.Render(r.DisplayName()),
-			MutedStyle.Render(fmt.Sprintf("(%s)", r.Type())))
+			MutedStyle.Render(fmt.Sprintf("(%s)", r.Type())),
+			ProtectedStyle.Render(sweep.GetProtectReason(r)))
+	}
+	return s
+}
+
+// RenderDryRun renders what would be deleted in dry-run mode, grouped by
+// type with per-type subtotals and the total reclaimable space. When the
+// analysis result is known (it may be nil), it also shows how many resources
+// of each type are left afterwards.
+func RenderDryRun(resources []sweep.Resource, result *sweep.Result) string {
+	var s string
+	var total int64
+	for _, r := range resources {
+		total += r.Size()
+	}
+
+	if quiet {
+		for _, r := range resources {
+			s += fmt.Sprintf("would delete %s %s\n", r.Type(), r.DisplayName())
+		}
+		if total > 0 {
+			s += fmt.Sprintf("would reclaim %s\n", FormatSize(total))
+		}
+		return s
+	}
+
+	s += fmt.Sprintf("\n  %s\n\n", WarningStyle.Render("Dry run - would delete:"))
+
+	types := []sweep.ResourceType{sweep.TypeContainer, sweep.TypeImage, sweep.TypeVolume, sweep.TypeNetwork}
+	byType := make(map[sweep.ResourceType][]sweep.Resource)
+	for _, r := range resources {
+		byType[r.Type()] = append(byType[r.Type()], r)
+	}
+
+	for _, t := range types {
+		group := byType[t]
+		if len(group) == 0 {
+			continue
+		}
+
+		var subtotal int64
+		unknown := 0
+		for _, r := range group {
+			subtotal += r.Size()
+			if r.Size() == 0 {
+				unknown++
+			}
+		}
+
+		header := typeHeader(t, len(group))
+		if t != sweep.TypeNetwork {
+			// Networks hold no data, so they have no size to report
+			header += " " + dryRunSize(subtotal, unknown)
+		}
+		s += fmt.Sprintf("  %s\n", header)
+
+		for _, r := range group {
+			line := fmt.Sprintf("    %s %s", CircleStyle.Render(), ResourceStyle.Render(r.DisplayName()))
+			if t != sweep.TypeNetwork {
+				if r.Size() > 0 {
+					line += " " + SizeStyle.Render(FormatSize(r.Size()))
+				} else {
+					line += " " + MutedStyle.Render("unknown")
+				}
+			}
+			s += line + "\n"
+		}
+		s += "\n"
+	}
+
+	if diff := dryRunDiff(resources, result); diff != "" {
+		s += fmt.Sprintf("  %s %s\n", MutedStyle.Render("Remaining:"), diff)
+	}
+	s += fmt.Sprintf("  %s %s\n\n",
+		MutedStyle.Render("Reclaimable space:"),
+		SizeStyle.Render("~"+FormatSize(total)))
+	return s
+}
+
+// dryRunDiff renders the analyzed count of each type before and after the
+// deletion, e.g. "containers 12 → 3, images 40 → 22"
+func dryRunDiff(resources []sweep.Resource, result *sweep.Result) string {
+	if result == nil {
+		return ""
+	}
+
+	before := make(map[sweep.ResourceType]int)
+	for _, r := range sweep.Dedupe(result.Resources()) {
+		before[r.Type()]++
+	}
+	deleted := make(map[sweep.ResourceType]int)
+	for _, r := range sweep.Dedupe(resources) {
+		deleted[r.Type()]++
+	}
+
+	var parts []string
+	for _, t := range []sweep.ResourceType{sweep.TypeContainer, sweep.TypeImage, sweep.TypeVolume, sweep.TypeNetwork} {
+		if before[t] == 0 {
+			continue
+		}
+		after := before[t] - deleted[t]
+		if after < 0 {
+			after = 0
+		}
+		parts = append(parts, fmt.Sprintf("%ss %d → %s", t, before[t], BoldStyle.Render(fmt.Sprint(after))))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// dryRunSize renders a subtotal, noting resources whose size is unknown
+func dryRunSize(subtotal int64, unknown int) string {
+	if subtotal == 0 {
+		return MutedStyle.Render("size unknown")
+	}
+	size := SizeStyle.Render(FormatSize(subtotal))
+	if unknown > 0 {
+		size += " " + MutedStyle.Render(fmt.Sprintf("(+%d unknown)", unknown))
+	}
+	return size
+}
+
+// RenderDetail renders the full detail view of one resource.
+func RenderDetail(r sweep.Resource) string {
+	fields := []sweep.DetailField{
+		{Name: "ID", Value: r.ID()},
+		{Name: "Status", Value: r.Details()},
+		{Name: "Category", Value: string(r.Category())},
+	}
+	if r.Size() > 0 {
+		fields = append(fields, sweep.DetailField{Name: "Size", Value: FormatSize(r.Size())})
+	}
+	if created := sweep.GetCreatedAt(r); !created.IsZero() {
+		fields = append(fields, sweep.DetailField{Name: "Created", Value: created.Local().Format(time.DateTime)})
+	}
+	fields = append(fields, sweep.GetInspectSummary(r)...)
+	if project := sweep.GetComposeProject(r); project != "" {
+		fields = append(fields, sweep.DetailField{Name: "Compose", Value: project})
+	}
+
+	nameWidth := 0
+	for _, f := range fields {
+		nameWidth = max(nameWidth, len(f.Name))
+	}
+
+	s := fmt.Sprintf("\n  %s %s\n\n",
+		BoldStyle.Render(r.DisplayName()),
+		MutedStyle.Render(fmt.Sprintf("(%s)", r.Type())))
+
+	for _, f := range fields {
+		if f.Value == "" {
+			continue
+		}
+		s += fmt.Sprintf("  %s  %s\n", MutedStyle.Render(padRight(f.Name, nameWidth)), f.Value)
+	}
+
+	if reason := sweep.GetProtectReason(r); reason != "" {
+		s += fmt.Sprintf("\n  %s %s\n", ProtectedStyle.Render("Protected:"), reason)
+	}
+
+	labels := sweep.GetLabels(r)
+	if len(labels) > 0 {
+		keys := make([]string, 0, len(labels))
+		for k := range labels {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		s += fmt.Sprintf("\n  %s\n", MutedStyle.Render("Labels:"))
+		for _, k := range keys {
+			s += fmt.Sprintf("    %s=%s\n", k, labels[k])
+		}
 	}
 
-	s += "\n"
 	return s
 }
 
 // FormatSize formats bytes into human readable string.
+// Units are decimal (1 GB = 1000 MB), matching docker's own output.
 func FormatSize(bytes int64) string {
 	const (
-		KB = 1024
-		MB = KB * 1024
-		GB = MB * 1024
+		KB = 1000
+		MB = KB * 1000
+		GB = MB * 1000
+		TB = GB * 1000
 	)
 
 	switch {
+	case bytes >= TB:
+		return fmt.Sprintf("%.1f TB", float64(bytes)/TB)
 	case bytes >= GB:
 		return fmt.Sprintf("%.1f GB", float64(bytes)/GB)
 	case bytes >= MB:
@@ -78,3 +339,54 @@ func FormatSize(bytes int64) string {
 		return fmt.Sprintf("%d B", bytes)
 	}
 }
+
+// RenderUsage renders the df summary: per type, how many resources are
+// suggested, unused and protected, and the space deleting the suggested ones frees.
+func RenderUsage(usage []sweep.TypeUsage) string {
+	var total sweep.CategoryUsage
+	for _, u := range usage {
+		total.Count += u.Suggested.Count
+		total.Size += u.Suggested.Size
+	}
+
+	if quiet {
+		var s string
+		for _, u := range usage {
+			s += fmt.Sprintf("%s suggested=%d (%s) unused=%d (%s) in_use=%d (%s) protected=%d (%s)\n", u.Type,
+				u.Suggested.Count, FormatSize(u.Suggested.Size),
+				u.Unused.Count, FormatSize(u.Unused.Size),
+				u.InUse.Count, FormatSize(u.InUse.Size),
+				u.Protected.Count, FormatSize(u.Protected.Size))
+		}
+		return s + fmt.Sprintf("reclaimable %s\n", FormatSize(total.Size))
+	}
+
+	const typeWidth, cellWidth = 12, 18
+	cell := func(c sweep.CategoryUsage) string {
+		text := fmt.Sprintf("%d", c.Count)
+		if c.Size > 0 {
+			text += "  " + FormatSize(c.Size)
+		}
+		return text
+	}
+
+	s := fmt.Sprintf("\n  %s%s%s%s%s\n",
+		MutedStyle.Render(padRight("TYPE", typeWidth)),
+		MutedStyle.Render(padRight("SUGGESTED", cellWidth)),
+		MutedStyle.Render(padRight("UNUSED", cellWidth)),
+		MutedStyle.Render(padRight("IN USE", cellWidth)),
+		MutedStyle.Render("PROTECTED"))
+	for _, u := range usage {
+		s += fmt.Sprintf("  %s%s%s%s%s\n",
+			BoldStyle.Render(padRight(string(u.Type)+"s", typeWidth)),
+			SuccessStyle.Render(padRight(cell(u.Suggested), cellWidth)),
+			padRight(cell(u.Unused), cellWidth),
+			padRight(cell(u.InUse), cellWidth),
+			ProtectedStyle.Render(cell(u.Protected)))
+	}
+
+	return s + RenderStatsBox([]string{
+		BoldStyle.Render(fmt.Sprintf("%d suggested", total.Count)),
+		SizeStyle.Render("~" + FormatSize(total.Size) + " reclaimable"),
+	}) + "\n"
+}
@@ -0,0 +1,226 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/midnattsol/docker-sweep/internal/sweep"
+)
+
+// resultRow is the outcome of one deletion attempt
+type resultRow struct {
+	resource sweep.Resource
+	err      error // nil when deleted
+	skipped  bool  // never attempted because of an interrupt
+}
+
+// ResultsModel is a bubbletea model listing the outcome of every resource
+// after a deletion, with the failures' errors under their names
+type ResultsModel struct {
+	rows         []resultRow
+	deleted      int
+	failed       int
+	skipped      int
+	reclaimed    int64
+	failuresOnly bool
+	scrollTop    int
+	termWidth    int
+	termHeight   int
+}
+
+// NewResults creates a results screen for resources deleted with errs, the
+// errors returned by sweep.DeleteResources
+func NewResults(resources []sweep.Resource, errs []error) ResultsModel {
+	failures := make(map[string]error, len(errs))
+	for _, err := range errs {
+		var de *sweep.DeleteError
+		if errors.As(err, &de) {
+			failures[string(de.Resource.Type())+"/"+de.Resource.ID()] = de.Err
+		}
+	}
+
+	var m ResultsModel
+	types := []sweep.ResourceType{sweep.TypeContainer, sweep.TypeImage, sweep.TypeVolume, sweep.TypeNetwork}
+	resources = sweep.Dedupe(resources)
+	for _, t := range types {
+		for _, r := range resources {
+			if r.Type() != t {
+				continue
+			}
+			row := resultRow{resource: r, err: failures[string(r.Type())+"/"+r.ID()]}
+			switch {
+			case errors.Is(row.err, sweep.ErrInterrupted):
+				row.skipped = true
+				m.skipped++
+			case row.err != nil:
+				m.failed++
+			default:
+				m.deleted++
+				m.reclaimed += r.Size()
+			}
+			m.rows = append(m.rows, row)
+		}
+	}
+	return m
+}
+
+func (m ResultsModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m ResultsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.termWidth = msg.Width
+		m.termHeight = msg.Height
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "esc", "enter", "ctrl+c":
+			return m, tea.Quit
+
+		case "up", "k":
+			m.scrollTop--
+
+		case "down", "j":
+			m.scrollTop++
+
+		case "pgup", "ctrl+u":
+			m.scrollTop -= m.viewportHeight()
+
+		case "pgdown", "ctrl+d", " ":
+			m.scrollTop += m.viewportHeight()
+
+		case "home", "g":
+			m.scrollTop = 0
+
+		case "end", "G":
+			m.scrollTop = len(m.lines())
+
+		case "f":
+			if m.failed > 0 || m.failuresOnly {
+				m.failuresOnly = !m.failuresOnly
+				m.scrollTop = 0
+			}
+		}
+	}
+
+	m.scrollTop = max(0, min(m.scrollTop, len(m.lines())-m.viewportHeight()))
+	return m, nil
+}
+
+// viewportHeight returns how many lines of the list fit between the summary
+// and the help footer
+func (m ResultsModel) viewportHeight() int {
+	height := m.termHeight
+	if height <= 0 {
+		height = 24
+	}
+	return max(height-9, 1)
+}
+
+// lines renders the list: a header per type, a line per resource and, for
+// failures, the error wrapped on the lines below
+func (m ResultsModel) lines() []string {
+	width := m.termWidth
+	if width <= 0 {
+		width = 80
+	}
+	errStyle := ErrorStyle.Width(max(width-8, 20))
+
+	types := []sweep.ResourceType{sweep.TypeContainer, sweep.TypeImage, sweep.TypeVolume, sweep.TypeNetwork}
+	var lines []string
+	for _, t := range types {
+		var section []string
+		count := 0
+		for _, row := range m.rows {
+			if row.resource.Type() != t || m.failuresOnly && (row.err == nil || row.skipped) {
+				continue
+			}
+			count++
+
+			name := ResourceStyle.Render(row.resource.DisplayName())
+			switch {
+			case row.skipped:
+				section = append(section, fmt.Sprintf("    %s %s %s", CircleStyle.Render(), name, MutedStyle.Render("not attempted")))
+			case row.err != nil:
+				section = append(section, fmt.Sprintf("    %s %s", CrossStyle.Render(), name))
+				for _, l := range strings.Split(errStyle.Render(row.err.Error()), "\n") {
+					section = append(section, "      "+l)
+				}
+			default:
+				line := fmt.Sprintf("    %s %s", CheckStyle.Render(), name)
+				if size := row.resource.Size(); size > 0 {
+					line += " " + SizeStyle.Render(FormatSize(size))
+				}
+				section = append(section, line)
+			}
+		}
+		if count == 0 {
+			continue
+		}
+		if len(lines) > 0 {
+			lines = append(lines, "")
+		}
+		lines = append(lines, "  "+typeHeader(t, count))
+		lines = append(lines, section...)
+	}
+	return lines
+}
+
+func (m ResultsModel) View() string {
+	var b strings.Builder
+
+	b.WriteString(RenderHeader())
+
+	summary := fmt.Sprintf("Deleted %s of %s resources",
+		SuccessStyle.Render(fmt.Sprintf("%d", m.deleted)),
+		BoldStyle.Render(fmt.Sprintf("%d", len(m.rows))))
+	if m.reclaimed > 0 {
+		summary += MutedStyle.Render(" · reclaimed ") + SizeStyle.Render(FormatSize(m.reclaimed))
+	}
+	if m.failed > 0 {
+		summary += MutedStyle.Render(" · ") + ErrorStyle.Render(fmt.Sprintf("%d failed", m.failed))
+	}
+	if m.skipped > 0 {
+		summary += MutedStyle.Render(" · ") + WarningStyle.Render(fmt.Sprintf("%d not attempted", m.skipped))
+	}
+	b.WriteString(fmt.Sprintf("\n  %s\n\n", summary))
+
+	lines := m.lines()
+	viewport := m.viewportHeight()
+	end := min(m.scrollTop+viewport, len(lines))
+	for _, line := range lines[m.scrollTop:end] {
+		b.WriteString(line + "\n")
+	}
+	for i := end - m.scrollTop; i < viewport; i++ {
+		b.WriteString("\n")
+	}
+
+	position := ""
+	if len(lines) > viewport {
+		position = MutedStyle.Render(fmt.Sprintf("  %d-%d of %d lines", m.scrollTop+1, end, len(lines)))
+	}
+	b.WriteString(fmt.Sprintf("\n  %s%s\n", Divider(60), position))
+
+	help := [][2]string{{"↑↓", "scroll"}}
+	if m.failed > 0 {
+		if m.failuresOnly {
+			help = append(help, [2]string{"f", "show all"})
+		} else {
+			help = append(help, [2]string{"f", "failures only"})
+		}
+	}
+	help = append(help, [2]string{"q", "close"})
+	b.WriteString(fmt.Sprintf("  %s\n\n", RenderHelp(help)))
+
+	return b.String()
+}
+
+// RunResults shows the outcome of a deletion until the user closes it
+func RunResults(resources []sweep.Resource, errs []error) error {
+	_, err := tea.NewProgram(NewResults(resources, errs), tea.WithAltScreen()).Run()
+	return err
+}
@@ -3,10 +3,11 @@ package ui
 import (
 	"fmt"
 	"os"
+	"strings"
+	"sync"
 
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/charmbracelet/lipgloss"
 	"golang.org/x/term"
 )
 
@@ -28,7 +29,7 @@ type SpinnerDoneMsg struct {
 func NewSpinner(message string) SpinnerModel {
 	s := spinner.New()
 	s.Spinner = spinner.Dot
-	s.Style = lipgloss.NewStyle().Foreground(Blue)
+	s.Style = SpinnerStyle
 	return SpinnerModel{
 		spinner: s,
 		message: message,
@@ -77,10 +78,27 @@ func IsTTY() bool {
 	return term.IsTerminal(int(os.Stdout.Fd()))
 }
 
+// IsInputTTY reports whether stdin is a terminal that can answer prompts
+func IsInputTTY() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+// silent disables spinner and progress output
+var silent bool
+
+// SetSilent turns spinner and progress output off, e.g. for machine-readable output
+func SetSilent(s bool) {
+	silent = s
+}
+
 // RunWithSpinner executes a function while showing a spinner
 // Returns error if the function fails or user cancels
 // Falls back to simple text output if not a TTY
 func RunWithSpinner(message string, fn func() error) error {
+	if silent || quiet {
+		return fn()
+	}
+
 	// Fallback for non-TTY environments
 	if !IsTTY() {
 		fmt.Printf("  %s %s\n", MutedStyle.Render("●"), MutedStyle.Render(message))
@@ -121,9 +139,10 @@ func RunWithSpinner(message string, fn func() error) error {
 	return nil
 }
 
-// MultiSpinner handles multiple sequential spinners
+// MultiSpinner handles multiple spinners, run sequentially or concurrently
 type MultiSpinner struct {
 	tasks []SpinnerTask
+	limit int
 }
 
 type SpinnerTask struct {
@@ -139,6 +158,11 @@ func (ms *MultiSpinner) Add(message string, fn func() error) {
 	ms.tasks = append(ms.tasks, SpinnerTask{Message: message, Fn: fn})
 }
 
+// SetConcurrency caps how many tasks RunConcurrent runs at once (0 means no cap)
+func (ms *MultiSpinner) SetConcurrency(n int) {
+	ms.limit = n
+}
+
 func (ms *MultiSpinner) Run() error {
 	for _, task := range ms.tasks {
 		if err := RunWithSpinner(task.Message, task.Fn); err != nil {
@@ -147,3 +171,166 @@ func (ms *MultiSpinner) Run() error {
 	}
 	return nil
 }
+
+// RunConcurrent runs all tasks in parallel and renders their spinners together.
+// Returns the first task error, or "cancelled" if the user quits.
+// Falls back to Run if not a TTY.
+func (ms *MultiSpinner) RunConcurrent() error {
+	if silent || quiet {
+		return ms.runTasks(func(int) {}, func(int, error) {})
+	}
+	if !IsTTY() {
+		return ms.Run()
+	}
+
+	m := newMultiSpinnerModel(ms.tasks)
+	p := tea.NewProgram(m)
+
+	go func() {
+		err := ms.runTasks(
+			func(i int) { p.Send(taskStartMsg{index: i}) },
+			func(i int, err error) { p.Send(taskDoneMsg{index: i, err: err}) },
+		)
+		p.Send(SpinnerDoneMsg{Err: err})
+	}()
+
+	finalModel, err := p.Run()
+	if err != nil {
+		return err
+	}
+
+	if fm, ok := finalModel.(multiSpinnerModel); ok {
+		if fm.quitting {
+			return fmt.Errorf("cancelled")
+		}
+		return fm.err
+	}
+
+	return nil
+}
+
+// runTasks runs every task in its own goroutine, honoring the concurrency cap,
+// and returns the first error
+func (ms *MultiSpinner) runTasks(onStart func(int), onDone func(int, error)) error {
+	limit := ms.limit
+	if limit <= 0 || limit > len(ms.tasks) {
+		limit = len(ms.tasks)
+	}
+	sem := make(chan struct{}, limit)
+
+	var wg sync.WaitGroup
+	var once sync.Once
+	var firstErr error
+
+	for i, task := range ms.tasks {
+		wg.Add(1)
+		go func(i int, task SpinnerTask) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			onStart(i)
+			err := task.Fn()
+			if err != nil {
+				once.Do(func() { firstErr = err })
+			}
+			onDone(i, err)
+		}(i, task)
+	}
+
+	wg.Wait()
+	return firstErr
+}
+
+type taskState int
+
+const (
+	taskPending taskState = iota
+	taskRunning
+	taskDone
+	taskFailed
+)
+
+type taskStartMsg struct {
+	index int
+}
+
+type taskDoneMsg struct {
+	index int
+	err   error
+}
+
+// multiSpinnerModel renders one line per task with a shared spinner
+type multiSpinnerModel struct {
+	spinner  spinner.Model
+	tasks    []SpinnerTask
+	states   []taskState
+	quitting bool
+	done     bool
+	err      error
+}
+
+func newMultiSpinnerModel(tasks []SpinnerTask) multiSpinnerModel {
+	s := spinner.New()
+	s.Spinner = spinner.Dot
+	s.Style = SpinnerStyle
+	return multiSpinnerModel{
+		spinner: s,
+		tasks:   tasks,
+		states:  make([]taskState, len(tasks)),
+	}
+}
+
+func (m multiSpinnerModel) Init() tea.Cmd {
+	return m.spinner.Tick
+}
+
+func (m multiSpinnerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "esc", "ctrl+c":
+			m.quitting = true
+			return m, tea.Quit
+		}
+
+	case taskStartMsg:
+		m.states[msg.index] = taskRunning
+
+	case taskDoneMsg:
+		if msg.err != nil {
+			m.states[msg.index] = taskFailed
+		} else {
+			m.states[msg.index] = taskDone
+		}
+
+	case SpinnerDoneMsg:
+		m.done = true
+		m.err = msg.Err
+		return m, tea.Quit
+
+	case spinner.TickMsg:
+		var cmd tea.Cmd
+		m.spinner, cmd = m.spinner.Update(msg)
+		return m, cmd
+	}
+
+	return m, nil
+}
+
+func (m multiSpinnerModel) View() string {
+	var b strings.Builder
+	for i, task := range m.tasks {
+		switch m.states[i] {
+		case taskDone:
+			b.WriteString(fmt.Sprintf("  %s %s\n", CheckStyle.Render(), task.Message))
+		case taskFailed:
+			b.WriteString(fmt.Sprintf("  %s %s\n", CrossStyle.Render(), task.Message))
+		case taskRunning:
+			b.WriteString(fmt.Sprintf("  %s %s\n", m.spinner.View(), MutedStyle.Render(task.Message)))
+		default:
+			b.WriteString(fmt.Sprintf("  %s %s\n", MutedStyle.Render("●"), MutedStyle.Render(task.Message)))
+		}
+	}
+	return b.String()
+}
@@ -4,6 +4,7 @@ import (
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
 )
 
 // Soft color palette - easier on the eyes
@@ -21,90 +22,68 @@ var (
 	LightGray = lipgloss.Color("#D1D5DB")
 )
 
-// Styles
+// Styles, set by applyTheme
 var (
-	// Title style
-	TitleStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(Blue)
-
-	// Subtitle / info
-	SubtitleStyle = lipgloss.NewStyle().
-			Foreground(LightGray)
-
-	// Success
-	SuccessStyle = lipgloss.NewStyle().
-			Foreground(Green)
-
-	// Warning
-	WarningStyle = lipgloss.NewStyle().
-			Foreground(Yellow)
-
-	// Error
-	ErrorStyle = lipgloss.NewStyle().
-			Foreground(Red)
-
-	// Muted / dim text
-	MutedStyle = lipgloss.NewStyle().
-			Foreground(Gray)
-
-	// Bold
-	BoldStyle = lipgloss.NewStyle().
-			Bold(true)
-
-	// Resource name (container, image, etc.)
-	ResourceStyle = lipgloss.NewStyle().
-			Foreground(Cyan).
-			Bold(true)
-
-	// Checkmark
-	CheckStyle = lipgloss.NewStyle().
-			Foreground(Green).
-			SetString("✓")
-
-	// Cross
-	CrossStyle = lipgloss.NewStyle().
-			Foreground(Red).
-			SetString("✗")
+	TitleStyle     lipgloss.Style // Title
+	SubtitleStyle  lipgloss.Style // Subtitle / info
+	SuccessStyle   lipgloss.Style
+	WarningStyle   lipgloss.Style
+	ErrorStyle     lipgloss.Style
+	MutedStyle     lipgloss.Style // Muted / dim text
+	BoldStyle      lipgloss.Style
+	ResourceStyle  lipgloss.Style // Resource name (container, image, etc.)
+	CheckStyle     lipgloss.Style // Checkmark
+	CrossStyle     lipgloss.Style // Cross
+	CircleStyle    lipgloss.Style // Circle (skipped)
+	BoxStyle       lipgloss.Style // Box for header/footer
+	DividerStyle   lipgloss.Style
+	SelectedStyle  lipgloss.Style // Selected item in list
+	CursorStyle    lipgloss.Style
+	HelpStyle      lipgloss.Style // Help text
+	SizeStyle      lipgloss.Style
+	ProtectedStyle lipgloss.Style
+	KeyStyle       lipgloss.Style // Keyboard key
+	SpinnerStyle   lipgloss.Style
+)
 
-	// Circle (skipped)
-	CircleStyle = lipgloss.NewStyle().
-			Foreground(Gray).
-			SetString("◦")
+func init() {
+	applyTheme(themes[ThemeDefault])
+}
 
-	// Box styles for header/footer
+// applyTheme rebuilds every style from t
+func applyTheme(t Theme) {
+	active = t
+
+	TitleStyle = lipgloss.NewStyle().Bold(true).Foreground(t.Title)
+	SubtitleStyle = lipgloss.NewStyle().Foreground(t.Text)
+	SuccessStyle = lipgloss.NewStyle().Foreground(t.Success)
+	WarningStyle = lipgloss.NewStyle().Foreground(t.Warning)
+	ErrorStyle = lipgloss.NewStyle().Foreground(t.Error)
+	MutedStyle = lipgloss.NewStyle().Foreground(t.Muted).Faint(t.Faint)
+	BoldStyle = lipgloss.NewStyle().Bold(true)
+	ResourceStyle = lipgloss.NewStyle().Foreground(t.Resource).Bold(true)
+	CheckStyle = lipgloss.NewStyle().Foreground(t.Success).SetString("✓")
+	CrossStyle = lipgloss.NewStyle().Foreground(t.Error).SetString("✗")
+	CircleStyle = lipgloss.NewStyle().Foreground(t.Muted).SetString("◦")
 	BoxStyle = lipgloss.NewStyle().
-			Border(lipgloss.RoundedBorder()).
-			BorderForeground(DarkGray).
-			Padding(0, 1)
-
-	// Divider
-	DividerStyle = lipgloss.NewStyle().
-			Foreground(DarkGray)
-
-	// Selected item in list
-	SelectedStyle = lipgloss.NewStyle().
-			Foreground(Purple).
-			Bold(true)
-
-	// Cursor
-	CursorStyle = lipgloss.NewStyle().
-			Foreground(Pink).
-			SetString("›")
-
-	// Help text
-	HelpStyle = lipgloss.NewStyle().
-			Foreground(LightGray)
-
-	// Size style
-	SizeStyle = lipgloss.NewStyle().
-			Foreground(Yellow)
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(t.Border).
+		Padding(0, 1)
+	DividerStyle = lipgloss.NewStyle().Foreground(t.Border)
+	SelectedStyle = lipgloss.NewStyle().Foreground(t.Selected).Bold(true).Underline(t.Faint)
+	CursorStyle = lipgloss.NewStyle().Foreground(t.Cursor).SetString("›")
+	HelpStyle = lipgloss.NewStyle().Foreground(t.Text)
+	SizeStyle = lipgloss.NewStyle().Foreground(t.Size)
+	ProtectedStyle = lipgloss.NewStyle().Foreground(t.Muted).Italic(true).Faint(t.Faint)
+	KeyStyle = lipgloss.NewStyle().Foreground(t.Resource).Bold(t.Faint)
+	SpinnerStyle = lipgloss.NewStyle().Foreground(t.Title)
+}
 
-	// Protected style
-	ProtectedStyle = lipgloss.NewStyle().
-			Foreground(Gray).
-			Italic(true)
-)
+// DisableColor makes every style render plain text, e.g. for NO_COLOR or --no-color.
+// Widths are unaffected, so padded columns still line up.
+func DisableColor() {
+	lipgloss.SetColorProfile(termenv.Ascii)
+}
 
 // Divider returns a horizontal divider line
 func Divider(width int) string {
@@ -128,10 +107,6 @@ func Indent(s string, n int) string {
 	return strings.Join(lines, "\n")
 }
 
-// KeyStyle renders a keyboard key
-var KeyStyle = lipgloss.NewStyle().
-	Foreground(Cyan)
-
 // RenderHelp renders a help line with styled keys
 // Format: key1 action1 · key2 action2 · ...
 func RenderHelp(items [][2]string) string {
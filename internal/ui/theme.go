@@ -0,0 +1,113 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Theme names accepted by SetTheme
+const (
+	ThemeDefault      = "default"
+	ThemeHighContrast = "high-contrast"
+	ThemeMono         = "mono"
+)
+
+// ThemeNames lists the themes in the order --theme documents them
+var ThemeNames = []string{ThemeDefault, ThemeHighContrast, ThemeMono}
+
+// Theme picks the color of every style and the checkbox symbols of the
+// picker. The symbols tell selected, unselected and protected rows apart on
+// their own, so a theme without colors stays usable.
+type Theme struct {
+	Title    lipgloss.TerminalColor
+	Text     lipgloss.TerminalColor // subtitles and help
+	Success  lipgloss.TerminalColor
+	Warning  lipgloss.TerminalColor
+	Error    lipgloss.TerminalColor
+	Muted    lipgloss.TerminalColor
+	Resource lipgloss.TerminalColor // resource names and keys
+	Border   lipgloss.TerminalColor // boxes and dividers
+	Selected lipgloss.TerminalColor
+	Cursor   lipgloss.TerminalColor
+	Size     lipgloss.TerminalColor
+
+	// Faint dims muted text and underlines the selected row instead, for
+	// themes whose colors carry nothing
+	Faint bool
+
+	CheckSelected   string
+	CheckUnselected string
+	CheckProtected  string
+}
+
+// active is the theme the styles were last built from
+var active Theme
+
+var themes = map[string]Theme{
+	ThemeDefault: {
+		Title:    Blue,
+		Text:     LightGray,
+		Success:  Green,
+		Warning:  Yellow,
+		Error:    Red,
+		Muted:    Gray,
+		Resource: Cyan,
+		Border:   DarkGray,
+		Selected: Purple,
+		Cursor:   Pink,
+		Size:     Yellow,
+
+		CheckSelected:   "▣",
+		CheckUnselected: "▢",
+		CheckProtected:  "▢",
+	},
+	// Okabe-Ito colors, which stay apart for the common kinds of color
+	// blindness, on brighter grays
+	ThemeHighContrast: {
+		Title:    lipgloss.Color("#56B4E9"),
+		Text:     lipgloss.Color("#FFFFFF"),
+		Success:  lipgloss.Color("#56B4E9"),
+		Warning:  lipgloss.Color("#E69F00"),
+		Error:    lipgloss.Color("#D55E00"),
+		Muted:    LightGray,
+		Resource: lipgloss.Color("#FFFFFF"),
+		Border:   Gray,
+		Selected: lipgloss.Color("#F0E442"),
+		Cursor:   lipgloss.Color("#F0E442"),
+		Size:     lipgloss.Color("#CC79A7"),
+
+		CheckSelected:   "▣",
+		CheckUnselected: "▢",
+		CheckProtected:  "⊘",
+	},
+	ThemeMono: {
+		Title:    lipgloss.NoColor{},
+		Text:     lipgloss.NoColor{},
+		Success:  lipgloss.NoColor{},
+		Warning:  lipgloss.NoColor{},
+		Error:    lipgloss.NoColor{},
+		Muted:    lipgloss.NoColor{},
+		Resource: lipgloss.NoColor{},
+		Border:   lipgloss.NoColor{},
+		Selected: lipgloss.NoColor{},
+		Cursor:   lipgloss.NoColor{},
+		Size:     lipgloss.NoColor{},
+		Faint:    true,
+
+		CheckSelected:   "[x]",
+		CheckUnselected: "[ ]",
+		CheckProtected:  "[-]",
+	},
+}
+
+// SetTheme rebuilds every style from the named theme
+func SetTheme(name string) error {
+	t, ok := themes[name]
+	if !ok {
+		return fmt.Errorf("invalid --theme %q: use %s", name, strings.Join(ThemeNames, ", "))
+	}
+	applyTheme(t)
+	return nil
+}
@@ -0,0 +1,46 @@
+package update
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// NoticeTTL is how long the result of a background check is reused
+const NoticeTTL = 24 * time.Hour
+
+// noticeState is the cached result of the last background check
+type noticeState struct {
+	Checked time.Time `json:"checked"`
+	Latest  string    `json:"latest,omitempty"`
+}
+
+// CheckThrottled returns the tag of a newer release, or "" when there is none.
+// GitHub is asked at most once per NoticeTTL; in between the answer comes from
+// statePath. Failures are not reported and are retried after the TTL.
+func CheckThrottled(ctx context.Context, statePath string) string {
+	var state noticeState
+	if data, err := os.ReadFile(statePath); err == nil {
+		_ = json.Unmarshal(data, &state)
+	}
+
+	if time.Since(state.Checked) >= NoticeTTL {
+		state = noticeState{Checked: time.Now()}
+		if rel, ok, err := CheckForUpdate(ctx); err == nil && ok {
+			state.Latest = rel.TagName
+		}
+		if data, err := json.Marshal(state); err == nil {
+			if err := os.MkdirAll(filepath.Dir(statePath), 0o700); err == nil {
+				_ = os.WriteFile(statePath, data, 0o600)
+			}
+		}
+	}
+
+	// The cached tag is stale once the user has updated to it or past it
+	if !isNewer(state.Latest, CurrentVersion) {
+		return ""
+	}
+	return state.Latest
+}
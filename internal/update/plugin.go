@@ -0,0 +1,85 @@
+package update
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// pluginName is the file name the docker CLI looks up for `docker sweep`
+const pluginName = "docker-sweep"
+
+// ErrPluginNotInstalled is returned by UninstallPlugin when there is nothing to remove
+var ErrPluginNotInstalled = errors.New("docker-sweep is not installed as a Docker CLI plugin")
+
+// PluginPath returns where the docker CLI loads the plugin from:
+// $DOCKER_CONFIG/cli-plugins/docker-sweep (or ~/.docker/cli-plugins/docker-sweep)
+func PluginPath() (string, error) {
+	dir := os.Getenv("DOCKER_CONFIG")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to find home directory: %w", err)
+		}
+		dir = filepath.Join(home, ".docker")
+	}
+	return filepath.Join(dir, "cli-plugins", pluginName), nil
+}
+
+// InstallPlugin copies the running binary to PluginPath. It returns the plugin
+// path and whether anything was copied: running the plugin itself is a no-op.
+func InstallPlugin() (string, bool, error) {
+	execPath, err := executablePath()
+	if err != nil {
+		return "", false, err
+	}
+	pluginPath, err := PluginPath()
+	if err != nil {
+		return "", false, err
+	}
+
+	if resolved, err := filepath.EvalSymlinks(pluginPath); err == nil && resolved == execPath {
+		return pluginPath, false, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(pluginPath), 0o755); err != nil {
+		return "", false, fmt.Errorf("failed to create plugin directory: %w", err)
+	}
+
+	// Copy next to the target and rename, so a running plugin is never half-written
+	tmpPath := pluginPath + ".new"
+	if err := copyFile(execPath, tmpPath); err != nil {
+		_ = os.Remove(tmpPath)
+		return "", false, fmt.Errorf("failed to copy binary (%s): %w", pluginPath, err)
+	}
+	if err := os.Chmod(tmpPath, 0o755); err != nil {
+		_ = os.Remove(tmpPath)
+		return "", false, fmt.Errorf("failed to set executable bit: %w", err)
+	}
+	if err := os.Rename(tmpPath, pluginPath); err != nil {
+		_ = os.Remove(tmpPath)
+		return "", false, fmt.Errorf("failed to install plugin (%s): %w", pluginPath, err)
+	}
+
+	return pluginPath, true, nil
+}
+
+// UninstallPlugin removes the plugin installed at PluginPath and returns its path
+func UninstallPlugin() (string, error) {
+	pluginPath, err := PluginPath()
+	if err != nil {
+		return "", err
+	}
+
+	if err := os.Remove(pluginPath); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return pluginPath, ErrPluginNotInstalled
+		}
+		return "", fmt.Errorf("failed to remove plugin (%s): %w", pluginPath, err)
+	}
+	// A backup left by update would otherwise outlive the plugin
+	_ = os.Remove(pluginPath + ".old")
+
+	return pluginPath, nil
+}
@@ -4,14 +4,19 @@ import (
 	"archive/tar"
 	"compress/gzip"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
+	"path"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/google/go-github/v60/github"
 )
@@ -37,9 +42,65 @@ type Asset struct {
 // CurrentVersion should be set by cmd.Execute.
 var CurrentVersion = "dev"
 
+// TimeoutEnv overrides how long a single updater request, download included,
+// may take (e.g. 30s, 5m) before giving up on a hung proxy or server.
+const TimeoutEnv = "DOCKER_SWEEP_HTTP_TIMEOUT"
+
+const defaultTimeout = 2 * time.Minute
+
+// newHTTPClient returns a client that honors HTTP_PROXY, HTTPS_PROXY and
+// NO_PROXY and bounds every request by the updater timeout.
+func newHTTPClient() (*http.Client, error) {
+	timeout := defaultTimeout
+	if env := strings.TrimSpace(os.Getenv(TimeoutEnv)); env != "" {
+		d, err := time.ParseDuration(env)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid %s value %q (expected a positive duration like 30s)", TimeoutEnv, env)
+		}
+		timeout = d
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+
+	return &http.Client{Transport: transport, Timeout: timeout}, nil
+}
+
+// isNewer reports whether the release tag latest is a later version than
+// current, comparing major, minor and patch numerically. A pre-release such as
+// 1.2.0-rc1 sorts before 1.2.0. Development builds never report an update.
+func isNewer(latest, current string) bool {
+	latest, current = strings.TrimPrefix(latest, "v"), strings.TrimPrefix(current, "v")
+	if current == "dev" || latest == "" {
+		return false
+	}
+
+	lv, lpre, _ := strings.Cut(latest, "-")
+	cv, cpre, _ := strings.Cut(current, "-")
+	ls, cs := strings.Split(lv, "."), strings.Split(cv, ".")
+	for i := 0; i < max(len(ls), len(cs)); i++ {
+		var l, c int
+		if i < len(ls) {
+			l, _ = strconv.Atoi(ls[i])
+		}
+		if i < len(cs) {
+			c, _ = strconv.Atoi(cs[i])
+		}
+		if l != c {
+			return l > c
+		}
+	}
+	// Same version: only a final release is newer than its pre-release
+	return lpre == "" && cpre != ""
+}
+
 // CheckForUpdate checks if a newer release is available.
 func CheckForUpdate(ctx context.Context) (*Release, bool, error) {
-	client := github.NewClient(nil)
+	httpClient, err := newHTTPClient()
+	if err != nil {
+		return nil, false, err
+	}
+	client := github.NewClient(httpClient)
 
 	rel, _, err := client.Repositories.GetLatestRelease(ctx, owner, repo)
 	if err != nil {
@@ -51,9 +112,7 @@ func CheckForUpdate(ctx context.Context) (*Release, bool, error) {
 		return nil, false, fmt.Errorf("failed to check for updates: %w", err)
 	}
 
-	latest := strings.TrimPrefix(rel.GetTagName(), "v")
-	current := strings.TrimPrefix(CurrentVersion, "v")
-	if latest == current || current == "dev" {
+	if !isNewer(rel.GetTagName(), CurrentVersion) {
 		return nil, false, nil
 	}
 
@@ -73,6 +132,9 @@ func CheckForUpdate(ctx context.Context) (*Release, bool, error) {
 	return r, true, nil
 }
 
+// checksumsAsset is the release asset listing the SHA256 of every archive
+const checksumsAsset = "checksums.txt"
+
 // GetAssetForPlatform returns the tar.gz URL for this OS/arch.
 func (r *Release) GetAssetForPlatform() (string, error) {
 	expected := fmt.Sprintf("docker-sweep-%s-%s.tar.gz", runtime.GOOS, runtime.GOARCH)
@@ -84,15 +146,33 @@ func (r *Release) GetAssetForPlatform() (string, error) {
 	return "", fmt.Errorf("no release found for %s/%s", runtime.GOOS, runtime.GOARCH)
 }
 
-// DownloadAndInstall downloads the release asset and replaces the current binary.
-func DownloadAndInstall(ctx context.Context, downloadURL string) error {
-	execPath, err := os.Executable()
+// GetChecksumAsset returns the URL of the release's checksums file.
+func (r *Release) GetChecksumAsset() (string, error) {
+	for _, a := range r.Assets {
+		if a.Name == checksumsAsset {
+			return a.DownloadURL, nil
+		}
+	}
+	return "", fmt.Errorf("release %s has no %s; refusing to install an unverified binary", r.TagName, checksumsAsset)
+}
+
+// DownloadAndInstall downloads the release asset, verifies it against the
+// checksums file and replaces the current binary.
+// With DOCKER_SWEEP_VERIFY=cosign the archive's signature is checked as well.
+func DownloadAndInstall(ctx context.Context, downloadURL, checksumsURL string) error {
+	mode, err := verifyMode()
 	if err != nil {
-		return fmt.Errorf("failed to get executable path: %w", err)
+		return err
 	}
-	execPath, err = filepath.EvalSymlinks(execPath)
+
+	client, err := newHTTPClient()
 	if err != nil {
-		return fmt.Errorf("failed to resolve executable path: %w", err)
+		return err
+	}
+
+	execPath, err := executablePath()
+	if err != nil {
+		return err
 	}
 
 	tmpDir, err := os.MkdirTemp("", "docker-sweep-update-*")
@@ -102,10 +182,23 @@ func DownloadAndInstall(ctx context.Context, downloadURL string) error {
 	defer os.RemoveAll(tmpDir)
 
 	archivePath := filepath.Join(tmpDir, "docker-sweep.tar.gz")
-	if err := downloadFile(ctx, downloadURL, archivePath); err != nil {
+	if err := downloadFile(ctx, client, downloadURL, archivePath); err != nil {
 		return fmt.Errorf("failed to download update: %w", err)
 	}
 
+	checksumsPath := filepath.Join(tmpDir, checksumsAsset)
+	if err := downloadFile(ctx, client, checksumsURL, checksumsPath); err != nil {
+		return fmt.Errorf("failed to download checksums: %w", err)
+	}
+	if err := verifyChecksum(archivePath, checksumsPath, path.Base(downloadURL)); err != nil {
+		return err
+	}
+	if mode == "cosign" {
+		if err := verifyCosign(ctx, client, downloadURL, archivePath, tmpDir); err != nil {
+			return err
+		}
+	}
+
 	binaryPath := filepath.Join(tmpDir, "docker-sweep")
 	if err := extractBinary(archivePath, binaryPath); err != nil {
 		return fmt.Errorf("failed to extract update: %w", err)
@@ -132,17 +225,101 @@ func DownloadAndInstall(ctx context.Context, downloadURL string) error {
 		return fmt.Errorf("failed to set binary permissions: %w", err)
 	}
 
-	_ = os.Remove(backupPath)
+	// The previous binary stays at backupPath for Rollback
+	return nil
+}
+
+// ErrNoBackup is returned by Rollback when no previous binary was kept
+var ErrNoBackup = errors.New("no previous version to roll back to")
+
+// Rollback restores the binary that the last DownloadAndInstall replaced. The
+// current binary becomes the backup, so a second Rollback undoes the first.
+func Rollback() error {
+	execPath, err := executablePath()
+	if err != nil {
+		return err
+	}
+
+	backupPath := execPath + ".old"
+	if _, err := os.Stat(backupPath); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("%w (%s not found)", ErrNoBackup, backupPath)
+		}
+		return fmt.Errorf("failed to read backup: %w", err)
+	}
+
+	swapPath := execPath + ".rollback"
+	_ = os.Remove(swapPath)
+	if err := os.Rename(execPath, swapPath); err != nil {
+		return fmt.Errorf("failed to move current binary (%s): %w", execPath, err)
+	}
+	if err := os.Rename(backupPath, execPath); err != nil {
+		_ = os.Rename(swapPath, execPath)
+		return fmt.Errorf("failed to restore backup (%s): %w", backupPath, err)
+	}
+	if err := os.Rename(swapPath, backupPath); err != nil {
+		return fmt.Errorf("failed to keep the replaced binary as backup: %w", err)
+	}
+
+	return nil
+}
+
+// executablePath returns the path of the running binary with symlinks resolved
+func executablePath() (string, error) {
+	execPath, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("failed to get executable path: %w", err)
+	}
+	execPath, err = filepath.EvalSymlinks(execPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve executable path: %w", err)
+	}
+	return execPath, nil
+}
+
+// verifyChecksum compares the SHA256 of archivePath with the entry for name in
+// a sha256sum-style checksums file
+func verifyChecksum(archivePath, checksumsPath, name string) error {
+	data, err := os.ReadFile(checksumsPath)
+	if err != nil {
+		return fmt.Errorf("failed to read checksums: %w", err)
+	}
+
+	var expected string
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			expected = strings.ToLower(fields[0])
+			break
+		}
+	}
+	if expected == "" {
+		return fmt.Errorf("no checksum for %s in %s", name, checksumsAsset)
+	}
+
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return fmt.Errorf("failed to hash update: %w", err)
+	}
+	if actual := hex.EncodeToString(h.Sum(nil)); actual != expected {
+		return fmt.Errorf("checksum mismatch for %s: expected %s, got %s; the download is corrupted or was tampered with", name, expected, actual)
+	}
 	return nil
 }
 
-func downloadFile(ctx context.Context, url, dest string) error {
+func downloadFile(ctx context.Context, client *http.Client, url, dest string) error {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return err
 	}
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := client.Do(req)
 	if err != nil {
 		return err
 	}
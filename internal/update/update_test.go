@@ -0,0 +1,33 @@
+package update
+
+import "testing"
+
+func TestIsNewer(t *testing.T) {
+	tests := []struct {
+		latest, current string
+		want            bool
+	}{
+		{"v1.2.0", "1.1.9", true},
+		{"v1.10.0", "v1.9.0", true},
+		{"v1.2.0", "v1.2.0", false},
+		{"1.2.0", "v1.2.0", false},
+		// A build ahead of the cached release is not told to downgrade
+		{"v1.2.0", "v1.3.0", false},
+		{"v1.2.0", "v1.2.0-rc1", true},
+		{"v1.2.0-rc1", "v1.2.0", false},
+		{"v1.2.1", "v1.2", true},
+		{"v1.2.0", "dev", false},
+		{"", "v1.0.0", false},
+	}
+	for _, tt := range tests {
+		if got := isNewer(tt.latest, tt.current); got != tt.want {
+			t.Errorf("isNewer(%q, %q) = %v, want %v", tt.latest, tt.current, got, tt.want)
+		}
+	}
+}
+
+func TestCosignPublicKeyParses(t *testing.T) {
+	if _, err := parsePublicKey(cosignPublicKey); err != nil {
+		t.Fatalf("embedded cosign.pub: %v", err)
+	}
+}
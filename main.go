@@ -9,7 +9,12 @@ import (
 	"github.com/midnattsol/docker-sweep/internal/docker"
 )
 
-var version = "dev"
+// Set through ldflags at release time
+var (
+	version = "dev"
+	commit  = ""
+	date    = ""
+)
 
 func main() {
 	// Docker CLI plugin metadata
@@ -36,5 +41,10 @@ func main() {
 		os.Exit(1)
 	}
 
-	cmd.Execute(version)
+	if err := docker.InitBackend(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	cmd.Execute(version, commit, date)
 }